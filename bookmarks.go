@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
+	log "github.com/dominickp/hn/logger"
+	"github.com/dominickp/hn/util"
+)
+
+// feedSaved is the feed of the user's bookmarks. Unlike the other feeds it's stored locally rather than fetched.
+const feedSaved client.Feed = "saved"
+
+// tabs are the feeds the user can switch between, in the order they're shown.
+var tabs = append(slices.Clone(client.Feeds), feedSaved)
+
+// bookmark is a story or comment the user saved. The item is stored in full, along with whatever of its comments had
+// been loaded, so it can be read offline.
+type bookmark struct {
+	Item    client.Item `json:"item"`
+	Tag     string      `json:"tag,omitempty"`
+	Note    string      `json:"note,omitempty"`
+	SavedAt time.Time   `json:"saved_at"`
+}
+
+// bookmarkStore is the user's bookmarks, most recently saved first.
+type bookmarkStore struct {
+	path      string // Where the bookmarks are saved, or "" to keep them in memory
+	bookmarks []bookmark
+}
+
+// loadBookmarks loads the bookmarks saved at path, or starts afresh if nothing has been saved there yet. An empty
+// path keeps the bookmarks in memory.
+func loadBookmarks(path string) (*bookmarkStore, error) {
+	s := &bookmarkStore{path: path}
+	return s, loadJSONFile(path, &s.bookmarks)
+}
+
+// get returns the bookmark of an item, if it's saved.
+func (s *bookmarkStore) get(itemId int) (bookmark, bool) {
+	if s == nil {
+		return bookmark{}, false
+	}
+	i := slices.IndexFunc(s.bookmarks, func(b bookmark) bool { return b.Item.Id == itemId })
+	if i < 0 {
+		return bookmark{}, false
+	}
+	return s.bookmarks[i], true
+}
+
+// find returns the saved copy of an item, which may be a comment within a saved story or thread.
+func (s *bookmarkStore) find(itemId int) (client.Item, bool) {
+	if s == nil {
+		return client.Item{}, false
+	}
+	for _, b := range s.bookmarks {
+		if item, ok := b.Item.Find(itemId); ok {
+			return item, true
+		}
+	}
+	return client.Item{}, false
+}
+
+// add saves a bookmark, replacing any earlier bookmark of the same item.
+func (s *bookmarkStore) add(b bookmark) {
+	s.remove(b.Item.Id)
+	s.bookmarks = slices.Insert(s.bookmarks, 0, b)
+}
+
+// remove deletes the bookmark of an item, reporting whether there was one.
+func (s *bookmarkStore) remove(itemId int) bool {
+	n := len(s.bookmarks)
+	s.bookmarks = slices.DeleteFunc(s.bookmarks, func(b bookmark) bool { return b.Item.Id == itemId })
+	return len(s.bookmarks) < n
+}
+
+// menuResponse returns the saved items for listing in the top menu.
+func (s *bookmarkStore) menuResponse() client.TopMenuResponse {
+	var response client.TopMenuResponse
+	if s == nil {
+		return response
+	}
+	for _, b := range s.bookmarks {
+		response.Items = append(response.Items, b.Item)
+	}
+	return response
+}
+
+// save writes the bookmarks to their path, if they have one.
+func (s *bookmarkStore) save() error {
+	if s == nil {
+		return nil
+	}
+	return saveJSONFile(s.path, s.bookmarks)
+}
+
+// saveDraft is a bookmark the user is filling in the tag and note of.
+type saveDraft struct {
+	item   client.Item
+	tag    string
+	note   string
+	onNote bool // Whether keys go to the note rather than the tag
+}
+
+// bookmarkMsg carries a bookmark ready to be saved, with as much of its item loaded as we could.
+type bookmarkMsg struct {
+	bookmark bookmark
+}
+
+// toggleBookmark starts saving an item, or removes its bookmark if it's already saved.
+func (m model) toggleBookmark(item client.Item) model {
+	if m.bookmarks.remove(item.Id) {
+		if err := m.bookmarks.save(); err != nil {
+			m.err = err
+		}
+		return m.refreshSaved()
+	}
+	m.saving = &saveDraft{item: item}
+	return m
+}
+
+// updateSaveDraft handles keys while the user is typing the tag and note of a bookmark.
+func (m model) updateSaveDraft(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	draft := m.saving
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.saving = nil
+	case tea.KeyEnter:
+		if !draft.onNote {
+			draft.onNote = true
+			break
+		}
+		m.saving = nil
+		b := bookmark{Item: draft.item, Tag: strings.TrimSpace(draft.tag), Note: strings.TrimSpace(draft.note), SavedAt: time.Now()}
+		return m, func() tea.Msg {
+			return checkBookmark(m.client, b)
+		}
+	default:
+		if draft.onNote {
+			draft.note, _ = editLine(draft.note, msg)
+		} else {
+			draft.tag, _ = editLine(draft.tag, msg)
+		}
+	}
+	return m, nil
+}
+
+// checkBookmark loads the item being saved along with its comments, so the bookmark can be read offline. If that
+// fails, the bookmark keeps whatever of the item we already had. Saving isn't part of a load, since the user expects
+// it to finish wherever they go.
+func checkBookmark(c *client.Client, b bookmark) tea.Msg {
+	item, err := c.GetItemTree(context.Background(), b.Item.Id, client.TreeOptions{MaxDepth: maxTopicDepth, MaxComments: maxTopicComments})
+	if err != nil {
+		log.Logger.Printf("Saving item %d without its comments: %v", b.Item.Id, err)
+	} else {
+		b.Item = item
+	}
+	return bookmarkMsg{bookmark: b}
+}
+
+// addBookmark saves a bookmark.
+func (m model) addBookmark(b bookmark) model {
+	m.bookmarks.add(b)
+	if err := m.bookmarks.save(); err != nil {
+		m.err = err
+	}
+	return m.refreshSaved()
+}
+
+// refreshSaved relists the bookmarks if we're looking at them.
+func (m model) refreshSaved() model {
+	if m.feed == feedSaved && m.getCurrentTopic() == nil && m.profile == nil && m.search == nil {
+		m.topMenuResponse = m.bookmarks.menuResponse()
+	}
+	if m.getCurrentTopic() != nil {
+		return m.setTopicChoices()
+	}
+	m.choices = getTopMenuCurrentPageChoices(m)
+	return m
+}
+
+// getBookmarkMarker returns the marker shown beside items which are saved, with their tag.
+func (m model) getBookmarkMarker(itemId int) string {
+	b, ok := m.bookmarks.get(itemId)
+	if !ok {
+		return ""
+	}
+	if b.Tag != "" {
+		return util.BookmarkStyle.Render(" ★ " + b.Tag)
+	}
+	return util.BookmarkStyle.Render(" ★")
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
+)
+
+func Test_bookmarkStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hn", "bookmarks.json")
+	s, err := loadBookmarks(path)
+	if err != nil {
+		t.Fatalf("loadBookmarks() of new bookmarks error = %v", err)
+	}
+	story := client.Item{Id: 1, Type: "story", Title: "one", Comments: []client.Item{{Id: 3, Type: "comment", Text: "hi"}}}
+	s.add(bookmark{Item: story, Tag: "read later"})
+	s.add(bookmark{Item: client.Item{Id: 2, Type: "comment", Text: "two"}})
+	s.add(bookmark{Item: story, Tag: "go"})
+	if err := s.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	loaded, err := loadBookmarks(path)
+	if err != nil {
+		t.Fatalf("loadBookmarks() error = %v", err)
+	}
+	// Saving an item again replaces its bookmark and moves it to the top
+	want := []client.Item{story, {Id: 2, Type: "comment", Text: "two"}}
+	if got := loaded.menuResponse().Items; !reflect.DeepEqual(got, want) {
+		t.Errorf("loadBookmarks() items = %+v, want %+v", got, want)
+	}
+	if b, ok := loaded.get(1); !ok || b.Tag != "go" {
+		t.Errorf("get() = %+v, %v, want the latest bookmark of the story", b, ok)
+	}
+	if !loaded.remove(2) || loaded.remove(2) {
+		t.Errorf("remove() should remove a bookmark once")
+	}
+}
+
+func Test_model_saveBookmark(t *testing.T) {
+	m := initialModel(client.New(client.WithOffline()))
+	m.topMenuResponse = client.TopMenuResponse{Items: []client.Item{{Id: 1, Type: "story", Title: "one"}}}
+
+	var cmd tea.Cmd
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("s")},
+		{Type: tea.KeyRunes, Runes: []rune("go")},
+		{Type: tea.KeyEnter},
+		{Type: tea.KeyRunes, Runes: []rune("a note")},
+		{Type: tea.KeyEnter},
+	} {
+		var updated tea.Model
+		updated, cmd = m.Update(key)
+		m = updated.(model)
+	}
+	if m.saving != nil || cmd == nil {
+		t.Fatalf("saving should finish after the note")
+	}
+	// The story can't be loaded offline, so it's saved as we had it
+	updated, _ := m.Update(cmd())
+	m = updated.(model)
+	b, ok := m.bookmarks.get(1)
+	if !ok || b.Tag != "go" || b.Note != "a note" || b.Item.Title != "one" {
+		t.Errorf("bookmark = %+v, %v, want the story saved with its tag and note", b, ok)
+	}
+
+	// Pressing s again unsaves it
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(model)
+	if _, ok := m.bookmarks.get(1); ok || m.saving != nil {
+		t.Errorf("s on a saved story should unsave it")
+	}
+}
+
+func Test_model_saveCommentFromTopic(t *testing.T) {
+	m := initialModel(client.New(client.WithOffline()))
+	m.topicHistoryStack = []client.Item{{Id: 1, Type: "story", Comments: []client.Item{{Id: 2, Type: "comment", Text: "hi"}}}}
+	m = m.setTopicChoices()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(model)
+	if !strings.Contains(m.footerView(), "Tag:") {
+		t.Errorf("footerView() = %q, want the tag prompt", m.footerView())
+	}
+
+	// Keys go to the prompt rather than working as shortcuts
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	m = updated.(model)
+	if m.saving == nil || m.saving.tag != "q" || !strings.Contains(m.footerView(), "Tag: q") {
+		t.Errorf("saving = %+v, want q typed into the tag", m.saving)
+	}
+}
+
+func Test_model_topicFromBookmarkOffline(t *testing.T) {
+	reply := client.Item{Id: 3, Type: "comment", Text: "reply"}
+	saved := client.Item{Id: 1, Type: "story", Title: "one", Comments: []client.Item{
+		{Id: 2, Type: "comment", Text: "hi", Comments: []client.Item{reply}},
+	}}
+	tests := []struct {
+		name   string
+		loaded client.Item
+		want   client.Item
+	}{
+		{name: "TestStoryUnavailable", loaded: client.Item{Id: 1, Unavailable: true}, want: saved},
+		{
+			name:   "TestCommentsUnavailable",
+			loaded: client.Item{Id: 1, Type: "story", Title: "one", Comments: []client.Item{{Id: 2, Unavailable: true}}},
+			want:   saved,
+		},
+		{name: "TestSavedComment", loaded: client.Item{Id: 3, Unavailable: true}, want: reply},
+		{name: "TestComplete", loaded: client.Item{Id: 1, Type: "story", Title: "one, edited"}, want: client.Item{Id: 1, Type: "story", Title: "one, edited"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := initialModel(client.New(client.WithOffline()))
+			m.bookmarks.add(bookmark{Item: saved})
+
+			updated, _ := m.Update(topicMsg{item: tt.loaded})
+			m = updated.(model)
+			if got := m.getCurrentTopic(); got == nil || !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("topic = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_exportBookmarks(t *testing.T) {
+	savedAt := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	bookmarks := []bookmark{
+		{Item: client.Item{Id: 1, Type: "story", Title: "Go", By: "rsc", Score: 5, Url: "https://go.dev"}, Tag: "lang", Note: "read it", SavedAt: savedAt},
+		{Item: client.Item{Id: 2, Type: "story", Title: "Ask HN: Why?", By: "pg", Score: 3}, SavedAt: savedAt},
+		{Item: client.Item{Id: 3, Type: "comment", By: "dang", Text: "<p>Please <i>don't</i>"}, SavedAt: savedAt},
+	}
+
+	var markdown bytes.Buffer
+	if err := exportBookmarksMarkdown(bookmarks, &markdown); err != nil {
+		t.Fatalf("exportBookmarksMarkdown() error = %v", err)
+	}
+	want := "# Saved from Hacker News\n" +
+		"\n- [Go](https://go.dev) by rsc, 5 points, [discussion](https://news.ycombinator.com/item?id=1)\n" +
+		"  - Saved 2024-03-04, tagged `lang`\n" +
+		"  - read it\n" +
+		"\n- [Ask HN: Why?](https://news.ycombinator.com/item?id=2) by pg, 3 points\n" +
+		"  - Saved 2024-03-04\n" +
+		"\n- [Comment by dang](https://news.ycombinator.com/item?id=3): Please don't\n" +
+		"  - Saved 2024-03-04\n"
+	if got := markdown.String(); got != want {
+		t.Errorf("exportBookmarksMarkdown() = %q, want %q", got, want)
+	}
+
+	var json bytes.Buffer
+	if err := exportBookmarksJSON(nil, &json); err != nil || strings.TrimSpace(json.String()) != "[]" {
+		t.Errorf("exportBookmarksJSON() of no bookmarks = %q, %v, want an empty array", json.String(), err)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
+)
+
+var (
+	// errNoOpener is returned when there's no way to open links on this system.
+	errNoOpener = errors.New("no browser or opener found")
+	// errNotWebLink is returned for links which aren't http or https URLs, which we won't open.
+	errNotWebLink = errors.New("not a web link")
+)
+
+// browser opens links in the user's browser, or copies them to the clipboard if it can't. Its fields are swapped out
+// in tests, so nothing is launched.
+type browser struct {
+	goos      string
+	getenv    func(string) string
+	lookPath  func(string) (string, error)
+	launch    func(name string, args ...string) error // Starts a command without waiting for it
+	clipboard io.Writer                               // Where OSC 52 sequences to copy links are written
+}
+
+// newBrowser returns a browser which uses the platform's opener.
+func newBrowser() *browser {
+	return &browser{
+		goos:      runtime.GOOS,
+		getenv:    os.Getenv,
+		lookPath:  exec.LookPath,
+		launch:    startCommand,
+		clipboard: os.Stderr, // Bubble Tea owns stdout, but both go to the terminal
+	}
+}
+
+// startCommand starts a command in the background, reaping it when it exits.
+func startCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// command returns the command which opens url: $BROWSER if it's set, otherwise the platform's opener.
+func (b *browser) command(url string) (string, []string, error) {
+	if browsers := b.getenv("BROWSER"); browsers != "" {
+		// $BROWSER is a list of commands separated by colons, which take the URL in place of any %s
+		for _, browser := range strings.Split(browsers, ":") {
+			fields := strings.Fields(browser)
+			if len(fields) == 0 {
+				continue
+			}
+			if _, err := b.lookPath(fields[0]); err != nil {
+				continue
+			}
+			args := fields[1:]
+			if strings.Contains(browser, "%s") {
+				for i := range args {
+					args[i] = strings.ReplaceAll(args[i], "%s", url)
+				}
+			} else {
+				args = append(args, url)
+			}
+			return fields[0], args, nil
+		}
+	}
+
+	var name string
+	var args []string
+	switch b.goos {
+	case "darwin":
+		name = "open"
+	case "windows":
+		name, args = "rundll32", []string{"url.dll,FileProtocolHandler"}
+	default:
+		name = "xdg-open"
+	}
+	if _, err := b.lookPath(name); err != nil {
+		return "", nil, errNoOpener
+	}
+	return name, append(args, url), nil
+}
+
+// isWebLink reports whether link is an http or https URL, the only kind we'll hand to the browser. Links come from
+// the API, and openers will happily run files or other handlers for anything else.
+func isWebLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "http" || scheme == "https"
+}
+
+// open opens url in the browser. If there's no way to, or it isn't a web link, the link is copied to the clipboard
+// instead, which open reports.
+func (b *browser) open(url string) (copied bool, err error) {
+	name, args, err := b.command(url)
+	if !isWebLink(url) {
+		name, args, err = "", nil, errNotWebLink
+	}
+	if err == nil {
+		err = b.launch(name, args...)
+	}
+	if err == nil {
+		return false, nil
+	}
+	if copyErr := b.copy(url); copyErr != nil {
+		return false, fmt.Errorf("%w, and couldn't copy the link: %v", err, copyErr)
+	}
+	return true, nil
+}
+
+// copy copies text to the clipboard using the OSC 52 escape sequence, which most terminals support, even over SSH.
+func (b *browser) copy(text string) error {
+	seq := osc52.New(text)
+	if b.getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(b.getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(b.clipboard)
+	return err
+}
+
+// openedMsg is sent once a link has been opened or copied, or we've failed to do either.
+type openedMsg struct {
+	url    string
+	copied bool
+	err    error
+}
+
+// openLink opens a link in the browser.
+func (m model) openLink(url string) tea.Cmd {
+	if url == "" || m.browser == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		copied, err := m.browser.open(url)
+		return openedMsg{url: url, copied: copied, err: err}
+	}
+}
+
+// cursorLinks returns the URL of the story under the cursor, or the topic we're viewing, and the URL of its
+// discussion. Comments, self posts and stories whose URL isn't a web link link to their discussion.
+func (m model) cursorLinks() (url, discussion string) {
+	var item client.Item
+	if topic := m.getCurrentTopic(); topic != nil {
+		item = *topic
+	} else if items, i := m.listedItems(), (m.currentPage-1)*m.pageSize+m.cursor; i < len(items) {
+		item = items[i]
+	} else {
+		return "", ""
+	}
+	if isWebLink(item.Url) {
+		return item.Url, item.DiscussionUrl()
+	}
+	return item.DiscussionUrl(), item.DiscussionUrl()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
+)
+
+// launched is a command a test browser was asked to start.
+type launched struct {
+	name string
+	args []string
+}
+
+// newTestBrowser returns a browser on goos with the given environment and commands installed, which records what it
+// launches and copies rather than doing it.
+func newTestBrowser(goos string, env map[string]string, installed []string, launches *[]launched, clipboard *bytes.Buffer) *browser {
+	return &browser{
+		goos:   goos,
+		getenv: func(key string) string { return env[key] },
+		lookPath: func(name string) (string, error) {
+			for _, command := range installed {
+				if command == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		},
+		launch: func(name string, args ...string) error {
+			*launches = append(*launches, launched{name: name, args: args})
+			return nil
+		},
+		clipboard: clipboard,
+	}
+}
+
+func Test_browser_open(t *testing.T) {
+	const url = "https://example.com/a"
+	tests := []struct {
+		name       string
+		goos       string
+		env        map[string]string
+		installed  []string
+		want       []launched
+		wantCopied string
+	}{
+		{
+			name:      "TestLinux",
+			goos:      "linux",
+			installed: []string{"xdg-open"},
+			want:      []launched{{name: "xdg-open", args: []string{url}}},
+		},
+		{
+			name:      "TestMac",
+			goos:      "darwin",
+			installed: []string{"open"},
+			want:      []launched{{name: "open", args: []string{url}}},
+		},
+		{
+			name:      "TestBrowserEnv",
+			goos:      "linux",
+			env:       map[string]string{"BROWSER": "missing:firefox --new-tab"},
+			installed: []string{"xdg-open", "firefox"},
+			want:      []launched{{name: "firefox", args: []string{"--new-tab", url}}},
+		},
+		{
+			name:      "TestBrowserEnvPlaceholder",
+			goos:      "linux",
+			env:       map[string]string{"BROWSER": "w3m -o %s"},
+			installed: []string{"w3m"},
+			want:      []launched{{name: "w3m", args: []string{"-o", url}}},
+		},
+		{
+			name:       "TestNoOpenerCopies",
+			goos:       "linux",
+			wantCopied: "\x1b]52;c;aHR0cHM6Ly9leGFtcGxlLmNvbS9h\x07",
+		},
+		{
+			name:       "TestNoOpenerCopiesInTmux",
+			goos:       "linux",
+			env:        map[string]string{"TMUX": "/tmp/tmux"},
+			wantCopied: "\x1bPtmux;\x1b\x1b]52;c;aHR0cHM6Ly9leGFtcGxlLmNvbS9h\x07\x1b\\",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var launches []launched
+			var clipboard bytes.Buffer
+			b := newTestBrowser(tt.goos, tt.env, tt.installed, &launches, &clipboard)
+
+			copied, err := b.open(url)
+			if err != nil {
+				t.Fatalf("open() error = %v", err)
+			}
+			if !reflect.DeepEqual(launches, tt.want) {
+				t.Errorf("open() launched %v, want %v", launches, tt.want)
+			}
+			if copied != (tt.wantCopied != "") || clipboard.String() != tt.wantCopied {
+				t.Errorf("open() copied = %v, %q, want %q", copied, clipboard.String(), tt.wantCopied)
+			}
+		})
+	}
+}
+
+func Test_browser_openNotWebLink(t *testing.T) {
+	for _, link := range []string{"file:///etc/passwd", "javascript:alert(1)", "ssh://example.com", "/etc/passwd"} {
+		var launches []launched
+		var clipboard bytes.Buffer
+		b := newTestBrowser("linux", nil, []string{"xdg-open"}, &launches, &clipboard)
+
+		copied, err := b.open(link)
+		if err != nil || !copied || clipboard.Len() == 0 {
+			t.Errorf("open(%q) = %v, %v, want the link copied", link, copied, err)
+		}
+		if len(launches) != 0 {
+			t.Errorf("open(%q) launched %v, want nothing launched", link, launches)
+		}
+	}
+}
+
+func Test_model_openLinks(t *testing.T) {
+	var launches []launched
+	var clipboard bytes.Buffer
+	m := initialModel(nil)
+	m.browser = newTestBrowser("linux", nil, []string{"xdg-open"}, &launches, &clipboard)
+	m.topMenuResponse = client.TopMenuResponse{Items: []client.Item{
+		{Id: 1, Type: "story", Url: "https://example.com"},
+		{Id: 2, Type: "story", Title: "Ask HN: self post"},
+		{Id: 3, Type: "story", Url: "file:///etc/passwd"},
+	}}
+
+	for _, tt := range []struct {
+		key    string
+		cursor int
+		want   string
+	}{
+		{key: "o", cursor: 0, want: "https://example.com"},
+		{key: "O", cursor: 0, want: "https://news.ycombinator.com/item?id=1"},
+		{key: "o", cursor: 1, want: "https://news.ycombinator.com/item?id=2"},
+		{key: "o", cursor: 2, want: "https://news.ycombinator.com/item?id=3"},
+	} {
+		launches = nil
+		m.cursor = tt.cursor
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+		if cmd == nil {
+			t.Fatalf("%s should open a link", tt.key)
+		}
+		if msg := cmd().(openedMsg); msg.err != nil || msg.copied {
+			t.Errorf("%s = %+v, want the link opened", tt.key, msg)
+		}
+		if len(launches) != 1 || !strings.Contains(strings.Join(launches[0].args, " "), tt.want) {
+			t.Errorf("%s on item %d launched %v, want %s opened", tt.key, tt.cursor, launches, tt.want)
+		}
+	}
+}
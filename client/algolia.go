@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultSearchURIPrefix = "https://hn.algolia.com/api/v1/"
+
+// Tags which limit what HN Search returns. Use AuthorTag and StoryTag for the tags with a parameter.
+const (
+	TagStory   = "story"
+	TagComment = "comment"
+	TagPoll    = "poll"
+	TagPollOpt = "pollopt"
+	TagJob     = "job"
+	TagAskHN   = "ask_hn"
+	TagShowHN  = "show_hn"
+	TagFront   = "front_page"
+)
+
+// AuthorTag limits a search to items by the given user.
+func AuthorTag(user string) string {
+	return "author_" + user
+}
+
+// StoryTag limits a search to a story and the comments on it.
+func StoryTag(storyId int) string {
+	return fmt.Sprintf("story_%d", storyId)
+}
+
+// WithSearchURL sets the prefix of the HN Search API searches are made against, e.g. "https://hn.algolia.com/api/v1/".
+func WithSearchURL(searchURL string) Option {
+	return func(c *Client) {
+		if !strings.HasSuffix(searchURL, "/") {
+			searchURL += "/"
+		}
+		c.searchURL = searchURL
+	}
+}
+
+// SearchQuery is a search of everything on Hacker News, using the HN Search API.
+type SearchQuery struct {
+	Query         string
+	Tags          []string  // Tags every result must have, e.g. TagStory or AuthorTag("pg")
+	MinPoints     int       // The fewest points a result may have, 0 for no limit
+	MaxPoints     int       // The most points a result may have, 0 for no limit
+	CreatedAfter  time.Time // The earliest a result may have been posted, zero for no limit
+	CreatedBefore time.Time // The latest a result may have been posted, zero for no limit
+	ByDate        bool      // Sort the newest results first, rather than the most relevant
+	Page          int       // Which page of results to return, counting from 0
+	HitsPerPage   int       // How many results there are on a page, 0 for the API's default
+}
+
+// endpoint returns the URL of the search under the given prefix.
+func (q SearchQuery) endpoint(searchURL string) string {
+	values := url.Values{}
+	values.Set("query", q.Query)
+	if len(q.Tags) > 0 {
+		values.Set("tags", strings.Join(q.Tags, ","))
+	}
+	var numericFilters []string
+	if q.MinPoints > 0 {
+		numericFilters = append(numericFilters, fmt.Sprintf("points>=%d", q.MinPoints))
+	}
+	if q.MaxPoints > 0 {
+		numericFilters = append(numericFilters, fmt.Sprintf("points<=%d", q.MaxPoints))
+	}
+	if !q.CreatedAfter.IsZero() {
+		numericFilters = append(numericFilters, fmt.Sprintf("created_at_i>=%d", q.CreatedAfter.Unix()))
+	}
+	if !q.CreatedBefore.IsZero() {
+		numericFilters = append(numericFilters, fmt.Sprintf("created_at_i<=%d", q.CreatedBefore.Unix()))
+	}
+	if len(numericFilters) > 0 {
+		values.Set("numericFilters", strings.Join(numericFilters, ","))
+	}
+	if q.Page > 0 {
+		values.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.HitsPerPage > 0 {
+		values.Set("hitsPerPage", strconv.Itoa(q.HitsPerPage))
+	}
+
+	endpoint := "search"
+	if q.ByDate {
+		endpoint = "search_by_date"
+	}
+	return searchURL + endpoint + "?" + values.Encode()
+}
+
+// SearchPage is a page of results from the HN Search API.
+type SearchPage struct {
+	Items       []Item
+	Hits        int // How many results there are across every page
+	Page        int // Which page this is, counting from 0
+	Pages       int // How many pages of results there are
+	HitsPerPage int
+}
+
+// MenuResponse returns the results for listing in the top menu.
+func (p SearchPage) MenuResponse() TopMenuResponse {
+	return TopMenuResponse{Items: p.Items}
+}
+
+// searchResponse is the response of the HN Search API.
+type searchResponse struct {
+	Hits        []searchHit `json:"hits"`
+	NbHits      int         `json:"nbHits"`
+	Page        int         `json:"page"`
+	NbPages     int         `json:"nbPages"`
+	HitsPerPage int         `json:"hitsPerPage"`
+}
+
+// searchHit is a result from the HN Search API. Its fields are named differently to the Firebase API's, and any of
+// them may be null.
+type searchHit struct {
+	ObjectId    string   `json:"objectID"`
+	Tags        []string `json:"_tags"`
+	Author      string   `json:"author"`
+	CreatedAt   int      `json:"created_at_i"`
+	Title       string   `json:"title"`
+	Url         string   `json:"url"`
+	StoryText   string   `json:"story_text"`
+	CommentText string   `json:"comment_text"`
+	Points      *int     `json:"points"`
+	NumComments *int     `json:"num_comments"`
+	ParentId    *int     `json:"parent_id"`
+	Children    []int    `json:"children"`
+}
+
+// item returns the hit as an Item, as the Firebase API would describe it.
+func (h searchHit) item() (Item, error) {
+	id, err := strconv.Atoi(h.ObjectId)
+	if err != nil {
+		return Item{}, fmt.Errorf("bad search result ID %q: %w", h.ObjectId, err)
+	}
+	item := Item{
+		Id:    id,
+		By:    h.Author,
+		Time:  h.CreatedAt,
+		Title: h.Title,
+		Url:   h.Url,
+		Text:  h.StoryText,
+		Kids:  h.Children,
+	}
+	for _, itemType := range []string{TypeStory, TypeComment, TypePoll, TypePollOpt, TypeJob} {
+		if slices.Contains(h.Tags, itemType) {
+			item.Type = itemType
+			break
+		}
+	}
+	if item.IsComment() {
+		item.Text = h.CommentText
+	}
+	if h.Points != nil {
+		item.Score = *h.Points
+	}
+	if h.NumComments != nil {
+		item.Descendants = *h.NumComments
+	}
+	if h.ParentId != nil {
+		item.Parent = *h.ParentId
+	}
+	return item, nil
+}
+
+// GlobalSearch searches everything on Hacker News, not just what the client has seen, using the HN Search API. The
+// results are returned a page at a time, as Items like those of the Firebase API.
+func (c *Client) GlobalSearch(ctx context.Context, q SearchQuery) (SearchPage, error) {
+	if c.offline {
+		return SearchPage{}, fmt.Errorf("search: %w", ErrUnavailable)
+	}
+	c.logger.Printf("Searching for %q", q.Query)
+
+	// Searches aren't cached, since the results change as things are posted and voted on
+	body, err := c.fetchFrom(ctx, c.search, "GET", q.endpoint(c.searchURL), nil)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	var response searchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return SearchPage{}, err
+	}
+
+	page := SearchPage{Hits: response.NbHits, Page: response.Page, Pages: response.NbPages, HitsPerPage: response.HitsPerPage}
+	for _, hit := range response.Hits {
+		item, err := hit.item()
+		if err != nil {
+			c.logger.Printf("Skipping search result: %v", err)
+			continue
+		}
+		c.index.Add(item)
+		page.Items = append(page.Items, item)
+	}
+	return page, nil
+}
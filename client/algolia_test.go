@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSearchServer returns a stub of the HN Search API which answers every search with body, recording the requests
+// it gets.
+func newSearchServer(t *testing.T, body string, requests *[]*http.Request) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		if r.URL.Path != "/api/v1/search" && r.URL.Path != "/api/v1/search_by_date" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_GlobalSearch(t *testing.T) {
+	var requests []*http.Request
+	server := newSearchServer(t, `{
+		"hits": [
+			{"objectID": "1", "_tags": ["story", "author_pg", "story_1", "show_hn"], "author": "pg", "created_at_i": 1700000000,
+			 "title": "Show HN: a thing", "url": "https://example.com", "story_text": null, "points": 42, "num_comments": 7,
+			 "children": [2]},
+			{"objectID": "2", "_tags": ["comment", "author_rsc", "story_1"], "author": "rsc", "created_at_i": 1700000100,
+			 "comment_text": "Nice <i>thing</i>", "points": null, "parent_id": 1, "story_id": 1},
+			{"objectID": "oops", "_tags": ["story"]}
+		],
+		"nbHits": 41, "page": 1, "nbPages": 3, "hitsPerPage": 20
+	}`, &requests)
+	c := New(WithSearchURL(server.URL+"/api/v1"), WithLogger(log.New(io.Discard, "", 0)))
+
+	got, err := c.GlobalSearch(context.Background(), SearchQuery{Query: "thing", Page: 1})
+	if err != nil {
+		t.Fatalf("GlobalSearch() error = %v", err)
+	}
+	want := SearchPage{
+		Items: []Item{
+			{Id: 1, Type: TypeStory, By: "pg", Time: 1700000000, Title: "Show HN: a thing", Url: "https://example.com",
+				Score: 42, Descendants: 7, Kids: []int{2}},
+			{Id: 2, Type: TypeComment, By: "rsc", Time: 1700000100, Text: "Nice <i>thing</i>", Parent: 1},
+		},
+		Hits: 41, Page: 1, Pages: 3, HitsPerPage: 20,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GlobalSearch() = %+v, want %+v", got, want)
+	}
+	if results := c.Search("thing", 10); len(results) != 2 {
+		t.Errorf("GlobalSearch() results should be indexed, local search found %d", len(results))
+	}
+}
+
+func TestSearchQuery_endpoint(t *testing.T) {
+	after := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		query    SearchQuery
+		wantPath string
+		want     url.Values
+	}{
+		{
+			name:     "TestQueryOnly",
+			query:    SearchQuery{Query: "go generics"},
+			wantPath: "/api/v1/search",
+			want:     url.Values{"query": {"go generics"}},
+		},
+		{
+			name: "TestEverything",
+			query: SearchQuery{
+				Query:        "rust",
+				Tags:         []string{TagStory, AuthorTag("pg")},
+				MinPoints:    100,
+				MaxPoints:    500,
+				CreatedAfter: after,
+				ByDate:       true,
+				Page:         2,
+				HitsPerPage:  50,
+			},
+			wantPath: "/api/v1/search_by_date",
+			want: url.Values{
+				"query":          {"rust"},
+				"tags":           {"story,author_pg"},
+				"numericFilters": {"points>=100,points<=500,created_at_i>=1700000000"},
+				"page":           {"2"},
+				"hitsPerPage":    {"50"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*http.Request
+			server := newSearchServer(t, `{"hits": []}`, &requests)
+			c := New(WithSearchURL(server.URL+"/api/v1/"), WithLogger(log.New(io.Discard, "", 0)))
+			if _, err := c.GlobalSearch(context.Background(), tt.query); err != nil {
+				t.Fatalf("GlobalSearch() error = %v", err)
+			}
+			if len(requests) != 1 {
+				t.Fatalf("GlobalSearch() made %d requests, want 1", len(requests))
+			}
+			if got := requests[0].URL.Path; got != tt.wantPath {
+				t.Errorf("GlobalSearch() path = %s, want %s", got, tt.wantPath)
+			}
+			if got := requests[0].URL.Query(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GlobalSearch() query = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_GlobalSearchOffline(t *testing.T) {
+	c := New(WithOffline(), WithLogger(log.New(io.Discard, "", 0)))
+	if _, err := c.GlobalSearch(context.Background(), SearchQuery{Query: "go"}); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("GlobalSearch() error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestClient_GlobalSearchFailuresDontBlockTheAPI(t *testing.T) {
+	// HN Search is rate limiting us, but the Firebase API is fine
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		status, body := http.StatusOK, `{"id": 1, "type": "story"}`
+		if r.URL.Host == "search.test" {
+			status, body = http.StatusTooManyRequests, "slow down"
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+	})
+	c := New(
+		WithBaseURL("http://hn.test/v0"),
+		WithSearchURL("http://search.test/api/v1"),
+		WithTransport(transport),
+		WithRetries(1, 0, 0),
+		WithCircuitBreaker(2, time.Minute),
+		WithLogger(log.New(io.Discard, "", 0)),
+	)
+	c.sleep = func(context.Context, time.Duration) error { return nil }
+
+	for i := 0; i < 3; i++ {
+		c.GlobalSearch(context.Background(), SearchQuery{Query: "go"})
+	}
+	if _, err := c.GlobalSearch(context.Background(), SearchQuery{Query: "go"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("GlobalSearch() error = %v, want ErrCircuitOpen", err)
+	}
+	if stats := c.SearchStats(); stats.Breaker != BreakerOpen || stats.Retries != 1 {
+		t.Errorf("SearchStats() = %+v, want the breaker open after 1 retry", stats)
+	}
+
+	if _, err := c.GetItem(context.Background(), 1); err != nil {
+		t.Errorf("GetItem() error = %v, want search failures not to affect it", err)
+	}
+	if stats := c.Stats(); stats != (Stats{Breaker: BreakerClosed}) {
+		t.Errorf("Stats() = %+v, want no retries and the breaker closed", stats)
+	}
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"container/list"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFeedTTL       = time.Minute
+	defaultItemTTL       = 10 * time.Minute
+	defaultItemMaxStale  = 7 * 24 * time.Hour
+	defaultCacheMaxBytes = 64 << 20
+)
+
+// Cache is a persistent store of API responses on disk, keyed by endpoint. Feed lists and items are kept fresh for
+// separate TTLs, and the least recently used responses are evicted when the cache grows past its size cap.
+type Cache struct {
+	dir          string
+	feedTTL      time.Duration
+	itemTTL      time.Duration
+	itemMaxStale time.Duration
+	maxBytes     int64
+	now          func() time.Time
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List               // Keys of the cached responses, most recently used at the front
+	entries map[string]*list.Element // Elements of lru by key
+}
+
+// cacheEntry is a cached response as it's stored on disk.
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// lruEntry is a cached response as it's tracked in the LRU list.
+type lruEntry struct {
+	key  string
+	size int64
+}
+
+// CacheOption configures a Cache.
+type CacheOption func(*Cache)
+
+// WithFeedTTL sets how long feed lists are served from the cache before they're fetched again.
+func WithFeedTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.feedTTL = ttl
+	}
+}
+
+// WithItemTTL sets how long items are served from the cache before they're revalidated, and how long past that a
+// stale item may still be served while it's revalidated in the background.
+func WithItemTTL(ttl, maxStale time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.itemTTL = ttl
+		c.itemMaxStale = maxStale
+	}
+}
+
+// WithMaxBytes sets the size the cache may grow to before it starts evicting responses.
+func WithMaxBytes(maxBytes int64) CacheOption {
+	return func(c *Cache) {
+		c.maxBytes = maxBytes
+	}
+}
+
+// DefaultCacheDir returns the directory the cache lives in by default, under the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hn"), nil
+}
+
+// OpenCache opens the cache in dir, creating the directory if it doesn't exist.
+func OpenCache(dir string, opts ...CacheOption) (*Cache, error) {
+	c := &Cache{
+		dir:          dir,
+		feedTTL:      defaultFeedTTL,
+		itemTTL:      defaultItemTTL,
+		itemMaxStale: defaultItemMaxStale,
+		maxBytes:     defaultCacheMaxBytes,
+		now:          time.Now,
+		lru:          list.New(),
+		entries:      map[string]*list.Element{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files are touched whenever they're read, so their modification times give the LRU order of a previous run
+	type cachedFile struct {
+		key     string
+		size    int64
+		modTime time.Time
+	}
+	var cached []cachedFile
+	for _, file := range files {
+		key, err := url.PathUnescape(file.Name())
+		if file.IsDir() || err != nil || !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		cached = append(cached, cachedFile{key: key, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(cached, func(i, j int) bool { return cached[i].modTime.After(cached[j].modTime) })
+	for _, file := range cached {
+		c.entries[file.key] = c.lru.PushBack(&lruEntry{key: file.key, size: file.size})
+		c.size += file.size
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+	return c, nil
+}
+
+// path returns the path of the file the response for key is stored in.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, url.PathEscape(key))
+}
+
+// isItemKey reports whether key is the endpoint of a single item, as opposed to a feed list.
+func isItemKey(key string) bool {
+	return strings.HasPrefix(key, "item/")
+}
+
+// freshness describes how usable a cached response is.
+type freshness int
+
+const (
+	cacheMiss  freshness = iota // There's no usable response, it must be fetched
+	cacheStale                  // The response may be used, but should be revalidated
+	cacheFresh                  // The response may be used as is
+)
+
+// get returns the cached response for key and how fresh it is.
+func (c *Cache) get(key string) ([]byte, freshness) {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if ok {
+		c.lru.MoveToFront(element)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, cacheMiss
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		c.remove(key)
+		return nil, cacheMiss
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		c.remove(key)
+		return nil, cacheMiss
+	}
+	now := c.now()
+	_ = os.Chtimes(c.path(key), now, now)
+
+	age := now.Sub(entry.FetchedAt)
+	switch {
+	case !isItemKey(key) && age < c.feedTTL, isItemKey(key) && age < c.itemTTL:
+		return entry.Body, cacheFresh
+	case isItemKey(key) && age < c.itemTTL+c.itemMaxStale:
+		return entry.Body, cacheStale
+	}
+	return entry.Body, cacheMiss
+}
+
+// put stores the response for key, evicting the least recently used responses if the cache is over its size cap.
+func (c *Cache) put(key string, body []byte) error {
+	data, err := json.Marshal(cacheEntry{FetchedAt: c.now(), Body: body})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partially written response
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		c.size += int64(len(data)) - entry.size
+		entry.size = int64(len(data))
+		c.lru.MoveToFront(element)
+	} else {
+		c.entries[key] = c.lru.PushFront(&lruEntry{key: key, size: int64(len(data))})
+		c.size += int64(len(data))
+	}
+	c.evict()
+	return nil
+}
+
+// remove drops the response for key from the cache.
+func (c *Cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// evict removes the least recently used responses until the cache is within its size cap. c.mu must be held.
+func (c *Cache) evict() {
+	for c.size > c.maxBytes && c.lru.Len() > 0 {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// removeElement removes a response from the LRU list and from disk. c.mu must be held.
+func (c *Cache) removeElement(element *list.Element) {
+	entry := element.Value.(*lruEntry)
+	c.lru.Remove(element)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+	os.Remove(c.path(entry.key))
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for the cache.
+type fakeClock struct{ now time.Time }
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func TestCache_getAndPut(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	cache, err := OpenCache(t.TempDir(), WithFeedTTL(time.Minute), WithItemTTL(time.Hour, 24*time.Hour))
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	cache.now = clock.Now
+
+	if _, freshness := cache.get("item/1.json"); freshness != cacheMiss {
+		t.Errorf("get() of an empty cache = %v, want cacheMiss", freshness)
+	}
+	if err := cache.put("item/1.json", []byte(`{"id":1}`)); err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+	if err := cache.put("topstories.json", []byte(`[1]`)); err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		age   time.Duration
+		want  freshness
+		wantB string
+	}{
+		{name: "TestFreshItem", key: "item/1.json", age: 30 * time.Minute, want: cacheFresh, wantB: `{"id":1}`},
+		{name: "TestStaleItem", key: "item/1.json", age: 2 * time.Hour, want: cacheStale, wantB: `{"id":1}`},
+		{name: "TestExpiredItem", key: "item/1.json", age: 48 * time.Hour, want: cacheMiss, wantB: `{"id":1}`},
+		{name: "TestFreshFeed", key: "topstories.json", age: 30 * time.Second, want: cacheFresh, wantB: `[1]`},
+		{name: "TestExpiredFeed", key: "topstories.json", age: 2 * time.Minute, want: cacheMiss, wantB: `[1]`},
+	}
+	start := clock.now
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.now = start.Add(tt.age)
+			body, freshness := cache.get(tt.key)
+			if freshness != tt.want || string(body) != tt.wantB {
+				t.Errorf("get() = %s, %v, want %s, %v", body, freshness, tt.wantB, tt.want)
+			}
+		})
+	}
+}
+
+func TestCache_evict(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := OpenCache(dir)
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	body := []byte(`"` + strings.Repeat("x", 40) + `"`)
+	if err := cache.put("item/1.json", body); err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+	// Leave room for three responses
+	maxBytes := cache.size*3 + cache.size/2
+	cache.maxBytes = maxBytes
+	for _, key := range []string{"item/2.json", "item/3.json"} {
+		if err := cache.put(key, body); err != nil {
+			t.Fatalf("put() error = %v", err)
+		}
+	}
+	// Use item 1 so item 2 becomes the least recently used
+	cache.get("item/1.json")
+	if err := cache.put("item/4.json", body); err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+
+	// Reopen the cache to check the eviction made it to disk
+	cache, err = OpenCache(dir, WithMaxBytes(maxBytes))
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	for key, want := range map[string]bool{"item/1.json": true, "item/2.json": false, "item/4.json": true} {
+		if _, freshness := cache.get(key); (freshness != cacheMiss) != want {
+			t.Errorf("get(%s) cached = %v, want %v", key, freshness != cacheMiss, want)
+		}
+	}
+}
+
+func TestClient_handleRequestCached(t *testing.T) {
+	var requests atomic.Int32
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 1, "type": "story"}`)),
+			Request:    r,
+		}, nil
+	})
+	cache, err := OpenCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	c := New(WithBaseURL("http://hn.test/v0"), WithTransport(transport), WithCache(cache), WithLogger(log.New(io.Discard, "", 0)))
+
+	for i := 0; i < 3; i++ {
+		item, err := c.GetItem(context.Background(), 1)
+		if err != nil || item.Type != "story" {
+			t.Fatalf("GetItem() = %v, %v", item, err)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("GetItem() made %d requests, want 1", requests.Load())
+	}
+}
+
+func TestClient_handleRequestOffline(t *testing.T) {
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("offline client made a request to %s", r.URL)
+		return nil, http.ErrServerClosed
+	})
+	cache, err := OpenCache(t.TempDir(), WithItemTTL(0, 0))
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	if err := cache.put("item/1.json", []byte(`{"id": 1, "type": "story"}`)); err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+	c := New(WithTransport(transport), WithCache(cache), WithOffline(), WithLogger(log.New(io.Discard, "", 0)))
+
+	// Expired items are still served while offline
+	if item, err := c.GetItem(context.Background(), 1); err != nil || item.Type != "story" {
+		t.Errorf("GetItem() = %v, %v, want the cached story", item, err)
+	}
+	if _, err := c.GetItem(context.Background(), 2); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("GetItem() error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestClient_revalidateBounded(t *testing.T) {
+	var inFlight, most atomic.Int32
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			if m := most.Load(); n <= m || most.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		// The API hangs until the request is cancelled
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+	cache, err := OpenCache(t.TempDir(), WithItemTTL(0, time.Hour))
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	c := New(WithBaseURL("http://hn.test/v0"), WithTransport(transport), WithCache(cache), WithConcurrency(3),
+		WithLogger(log.New(io.Discard, "", 0)))
+
+	// A page of stale items is served from the cache, and refreshed a few at a time
+	for id := 1; id <= 20; id++ {
+		if err := cache.put(fmt.Sprintf("item/%d.json", id), []byte(fmt.Sprintf(`{"id": %d}`, id))); err != nil {
+			t.Fatalf("put() error = %v", err)
+		}
+		if _, err := c.GetItem(context.Background(), id); err != nil {
+			t.Fatalf("GetItem() error = %v", err)
+		}
+	}
+	for deadline := time.Now().Add(time.Second); inFlight.Load() < 3 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := most.Load(); got != 3 {
+		t.Errorf("revalidated %d items at once, want 3", got)
+	}
+
+	// Closing the client stops them
+	c.Close()
+	if got := inFlight.Load(); got != 0 {
+		t.Errorf("%d revalidations still in flight after Close()", got)
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const defaultConcurrency = 8
+
+// WithConcurrency sets how many requests may be in flight at once when fetching a batch of items.
+func WithConcurrency(n int) Option {
+	return func(c *Client) {
+		c.concurrency = max(1, n)
+	}
+}
+
+// ItemError records a failure to fetch a single item of a batch.
+type ItemError struct {
+	Id  int
+	Err error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Id, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// fanOut calls fn for every index in [0, n), running at most limit calls at once, and waits for them all to return.
+// Once ctx is cancelled no more calls are started, and fn is called with ctx's error for the indexes left.
+func fanOut(ctx context.Context, n, limit int, fn func(i int, err error)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(1, limit))
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			fn(i, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i, nil)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// GetItems fetches the items with the given IDs in parallel. The returned items are in the same order as itemIds.
+// A failed item is left as an Unavailable placeholder with only its ID set, and its *ItemError is included in the
+// joined error, so one bad item does not cost the rest of the batch.
+func (c *Client) GetItems(ctx context.Context, itemIds []int) ([]Item, error) {
+	items := make([]Item, len(itemIds))
+	errs := make([]error, len(itemIds))
+	fanOut(ctx, len(itemIds), c.concurrency, func(i int, err error) {
+		var item Item
+		if err == nil {
+			item, err = c.GetItem(ctx, itemIds[i])
+		}
+		if err != nil {
+			items[i] = Item{Id: itemIds[i], Unavailable: true}
+			errs[i] = &ItemError{Id: itemIds[i], Err: err}
+			return
+		}
+		items[i] = item
+	})
+	return items, errors.Join(errs...)
+}
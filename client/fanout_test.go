@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_GetItems(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/item/1.json": `{"id": 1, "type": "story"}`,
+		"/v0/item/3.json": `{"id": 3, "type": "story"}`,
+	})
+
+	got, err := c.GetItems(context.Background(), []int{3, 2, 1})
+
+	want := []Item{{Id: 3, Type: "story"}, {Id: 2, Unavailable: true}, {Id: 1, Type: "story"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetItems() = %v, want %v", got, want)
+	}
+	var itemErr *ItemError
+	if !errors.As(err, &itemErr) || itemErr.Id != 2 {
+		t.Errorf("GetItems() error = %v, want an *ItemError for item 2", err)
+	}
+}
+
+func Test_fanOut(t *testing.T) {
+	const limit = 3
+	var (
+		mu      sync.Mutex
+		seen    []int
+		running atomic.Int32
+		peak    atomic.Int32
+	)
+	fanOut(context.Background(), 20, limit, func(i int, err error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		seen = append(seen, i)
+		mu.Unlock()
+	})
+
+	if len(seen) != 20 {
+		t.Errorf("fanOut() ran %d calls, want 20", len(seen))
+	}
+	if peak.Load() > limit {
+		t.Errorf("fanOut() ran %d calls at once, want at most %d", peak.Load(), limit)
+	}
+}
+
+func TestClient_GetItemsCancelled(t *testing.T) {
+	c := newTestClient(t, map[string]string{"/v0/item/1.json": `{"id": 1, "type": "story"}`})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err := c.GetItems(ctx, []int{1})
+	if want := []Item{{Id: 1, Unavailable: true}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetItems() = %v, want %v", got, want)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("GetItems() error = %v, want context.Canceled", err)
+	}
+}
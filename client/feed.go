@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Feed is one of the story lists published by the Hacker News API, named after its endpoint.
+type Feed string
+
+const (
+	FeedTop  Feed = "topstories"
+	FeedNew  Feed = "newstories"
+	FeedBest Feed = "beststories"
+	FeedAsk  Feed = "askstories"
+	FeedShow Feed = "showstories"
+	FeedJob  Feed = "jobstories"
+)
+
+// Feeds lists every feed in the order they are presented to the user.
+var Feeds = []Feed{FeedTop, FeedNew, FeedBest, FeedAsk, FeedShow, FeedJob}
+
+// Name returns the short name of the feed, e.g. "top" for FeedTop.
+func (f Feed) Name() string {
+	return strings.TrimSuffix(string(f), "stories")
+}
+
+// ParseFeed returns the feed with the given short name (e.g. "ask") or endpoint name (e.g. "askstories").
+func ParseFeed(name string) (Feed, error) {
+	for _, feed := range Feeds {
+		if name == feed.Name() || name == string(feed) {
+			return feed, nil
+		}
+	}
+	return "", fmt.Errorf("unknown feed %q", name)
+}
+
+// GetFeed returns the IDs of the stories on the given feed, in feed order.
+func (c *Client) GetFeed(ctx context.Context, feed Feed) ([]int, error) {
+	c.logger.Printf("Getting %s stories", feed.Name())
+	var storyIds []int
+	err := c.handleRequest(ctx, "GET", string(feed)+".json", nil, &storyIds)
+	if err != nil {
+		return nil, err
+	}
+	return storyIds, nil
+}
+
+// GetFeedMenuResponse returns a menu response with the stories of the given feed as items with only their IDs.
+func (c *Client) GetFeedMenuResponse(ctx context.Context, feed Feed) (TopMenuResponse, error) {
+	storyIds, err := c.GetFeed(ctx, feed)
+	if err != nil {
+		return TopMenuResponse{}, err
+	}
+
+	topMenuResponse := TopMenuResponse{Items: make([]Item, 0, len(storyIds))}
+	for _, storyId := range storyIds {
+		topMenuResponse.Items = append(topMenuResponse.Items, Item{Id: storyId})
+	}
+	return topMenuResponse, nil
+}
@@ -1,24 +1,148 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
-	log "github.com/dominickp/hn/logger"
+	"github.com/dominickp/hn/logger"
 	"github.com/go-resty/resty/v2"
 )
 
+// ErrUnavailable is returned for requests which can't be served while offline because they were never cached.
+var ErrUnavailable = errors.New("not available offline")
+
 const (
 	defaultHackerNewsURIPrefix = "https://hacker-news.firebaseio.com/v0/"
+	defaultTimeout             = 5 * time.Second
 )
 
-var (
-	restyClient         *resty.Client
-	hackernewsURIPrefix string
-)
+// Client is a Hacker News API client. Build one with New.
+type Client struct {
+	restyClient *resty.Client
+	baseURL     string
+	logger      *log.Logger
+	concurrency int    // max requests in flight when fetching a batch of items
+	cache       *Cache // cache of responses, if any
+	offline     bool   // serve requests only from the cache
+
+	retryPolicy retryPolicy
+	api         *upstream                                  // the Firebase API
+	search      *upstream                                  // the HN Search API, see algolia.go
+	sleep       func(context.Context, time.Duration) error // waits between retries, swapped out in tests
+
+	revalidating  sync.Map        // endpoints being refreshed in the background
+	revalidations chan struct{}   // slots for background refreshes in flight, as many as concurrency allows
+	background    context.Context // the context of background refreshes, cancelled by Close
+	stop          context.CancelFunc
+	running       sync.WaitGroup // background refreshes in flight or waiting for a slot
+	index         *Index         // every item fetched so far, for searching
+	searchURL     string         // prefix of the HN Search API, see algolia.go
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets the API prefix requests are made against, e.g. "https://hacker-news.firebaseio.com/v0/".
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		c.baseURL = baseURL
+	}
+}
+
+// WithTimeout sets the timeout for each request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.restyClient.SetTimeout(timeout)
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to make requests.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.restyClient.SetTransport(transport)
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with each request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.restyClient.SetHeader("User-Agent", userAgent)
+	}
+}
+
+// WithCache serves requests from the given cache where possible, and stores responses in it.
+func WithCache(cache *Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithOffline serves every request from the cache, whatever its age, and never touches the network. Requests for
+// anything that isn't cached fail with ErrUnavailable.
+func WithOffline() Option {
+	return func(c *Client) {
+		c.offline = true
+	}
+}
+
+// WithLogger sets the logger requests are logged to.
+func WithLogger(l *log.Logger) Option {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// New returns a Client configured by opts. The base URL defaults to the HN_HOST environment variable, falling back
+// to the public Hacker News API, and the search URL to HN_SEARCH_HOST, falling back to the public HN Search API.
+func New(opts ...Option) *Client {
+	c := &Client{
+		restyClient: resty.New().
+			SetJSONMarshaler(json.Marshal).
+			SetJSONUnmarshaler(json.Unmarshal).
+			SetTimeout(defaultTimeout),
+		logger:      logger.Logger,
+		concurrency: defaultConcurrency,
+		retryPolicy: retryPolicy{
+			maxRetries: defaultMaxRetries,
+			baseDelay:  defaultRetryBaseDelay,
+			maxDelay:   defaultRetryMaxDelay,
+		},
+		api:    newUpstream(defaultBreakerThreshold, defaultBreakerCooldown),
+		search: newUpstream(defaultBreakerThreshold, defaultBreakerCooldown),
+		sleep:  sleepContext,
+		index:  NewIndex(),
+	}
+	WithBaseURL(getEnvString("HN_HOST", defaultHackerNewsURIPrefix))(c)
+	WithSearchURL(getEnvString("HN_SEARCH_HOST", defaultSearchURIPrefix))(c)
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.revalidations = make(chan struct{}, c.concurrency)
+	c.background, c.stop = context.WithCancel(context.Background())
+	return c
+}
+
+// Close stops any requests the client is making in the background, and waits for them to finish.
+func (c *Client) Close() {
+	c.stop()
+	c.running.Wait()
+}
+
+// Offline reports whether the client only serves requests from its cache.
+func (c *Client) Offline() bool {
+	return c.offline
+}
 
 // getEnvString returns the value of the environment variable named by the key,
 // or fallback if the environment variable is not set.
@@ -29,96 +153,238 @@ func getEnvString(key, fallback string) string {
 	return fallback
 }
 
-func init() {
-	hackernewsURIPrefix = getEnvString("HN_HOST", defaultHackerNewsURIPrefix)
-	restyClient = resty.New().
-		SetJSONMarshaler(json.Marshal).
-		SetJSONUnmarshaler(json.Unmarshal).
-		SetTimeout(time.Duration(5) * time.Second) // Set timeout to 5 seconds
+// refreshKey marks a context whose requests always go to the network rather than being served from the cache, though
+// their responses are still cached.
+type refreshKey struct{}
+
+// withRefresh returns a copy of ctx whose GET requests fetch fresh responses, ignoring anything cached.
+func withRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
 }
 
-// handleRequest is a helper function that handles the request to the 4channel API and captures fanout metrics.
-func handleRequest(method string, endpoint string, headers map[string]string, result interface{}) error {
-	response, err := restyClient.R().
-		SetHeaders(headers).
-		SetResult(result).
-		Execute(method, hackernewsURIPrefix+endpoint)
+// handleRequest is a helper function that handles the request to the Hacker News API, decoding the JSON response
+// into result. GET requests are served from the cache when it has a fresh enough response, unless ctx was made with
+// withRefresh.
+func (c *Client) handleRequest(ctx context.Context, method string, endpoint string, headers map[string]string, result interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.offline {
+		var body []byte
+		if c.cache != nil {
+			body, _ = c.cache.get(endpoint)
+		}
+		if body == nil {
+			return fmt.Errorf("%s: %w", endpoint, ErrUnavailable)
+		}
+		c.logger.Printf("Serving %s offline", endpoint)
+		return json.Unmarshal(body, result)
+	}
+
+	cacheable := c.cache != nil && method == "GET"
+	if cacheable && ctx.Value(refreshKey{}) == nil {
+		body, freshness := c.cache.get(endpoint)
+		switch freshness {
+		case cacheFresh:
+			c.logger.Printf("Cache hit for %s", endpoint)
+			return json.Unmarshal(body, result)
+		case cacheStale:
+			c.logger.Printf("Serving stale %s while revalidating", endpoint)
+			c.revalidate(method, endpoint, headers)
+			return json.Unmarshal(body, result)
+		}
+	}
 
-	log.Logger.Printf("Request to %s%s returned %d", hackernewsURIPrefix, endpoint, response.StatusCode())
+	body, err := c.fetch(ctx, method, endpoint, headers)
 	if err != nil {
 		return err
 	}
-	if response.IsError() {
-		return fmt.Errorf("error: %s", response.String())
+	if cacheable {
+		if err := c.cache.put(endpoint, body); err != nil {
+			c.logger.Printf("Error caching %s: %v", endpoint, err)
+		}
+	}
+	return json.Unmarshal(body, result)
+}
+
+// revalidate refreshes a stale cached response in the background, unless it's already being refreshed. It isn't
+// tied to the context of the request that found the response stale, so it finishes even if that request is cancelled,
+// but it is stopped by Close. Refreshes take turns with each other for as many slots as concurrency allows, so a page
+// of stale items doesn't send a flood of requests.
+func (c *Client) revalidate(method string, endpoint string, headers map[string]string) {
+	if c.background.Err() != nil {
+		return
+	}
+	if _, loaded := c.revalidating.LoadOrStore(endpoint, true); loaded {
+		return
+	}
+	c.running.Add(1)
+	go func() {
+		defer c.running.Done()
+		defer c.revalidating.Delete(endpoint)
+		select {
+		case c.revalidations <- struct{}{}:
+			defer func() { <-c.revalidations }()
+		case <-c.background.Done():
+			return
+		}
+
+		body, err := c.fetch(c.background, method, endpoint, headers)
+		if err != nil {
+			c.logger.Printf("Error revalidating %s: %v", endpoint, err)
+			return
+		}
+		if err := c.cache.put(endpoint, body); err != nil {
+			c.logger.Printf("Error caching %s: %v", endpoint, err)
+		}
+	}()
+}
+
+// fetch makes a request to the Hacker News API and returns the body of a successful response. Failed GET requests
+// are retried with backoff, and no requests are made while the circuit breaker is open.
+func (c *Client) fetch(ctx context.Context, method string, endpoint string, headers map[string]string) ([]byte, error) {
+	return c.fetchFrom(ctx, c.api, method, endpoint, headers)
+}
+
+// fetchFrom is fetch for requests to the given upstream API, which they count against.
+func (c *Client) fetchFrom(ctx context.Context, u *upstream, method string, endpoint string, headers map[string]string) ([]byte, error) {
+	for retry := 0; ; retry++ {
+		if err := u.breaker.allow(); err != nil {
+			c.logger.Printf("Request to %s not made: %v", c.url(endpoint), err)
+			return nil, err
+		}
+
+		body, err := c.fetchOnce(ctx, method, endpoint, headers)
+		if ctx.Err() != nil {
+			// We were cancelled, which says nothing about the health of the API
+			u.breaker.release()
+			return nil, ctx.Err()
+		}
+		if err == nil || !retryable(err) {
+			// Errors we won't retry, like a 404, say nothing about the health of the API
+			u.breaker.record(true)
+			return body, err
+		}
+		if state := u.breaker.record(false); state == BreakerOpen {
+			c.logger.Printf("Circuit breaker opened after request to %s failed", c.url(endpoint))
+			return nil, err
+		}
+		if method != "GET" || retry >= c.retryPolicy.maxRetries {
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			retryAfter = statusErr.RetryAfter
+		}
+		delay := c.retryPolicy.backoff(retry, retryAfter)
+		u.retried()
+		c.logger.Printf("Retrying %s in %v (retry %d of %d): %v",
+			c.url(endpoint), delay, retry+1, c.retryPolicy.maxRetries, err)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
 	}
-	return nil
 }
 
-func GetTopStories() ([]int, error) {
-	log.Logger.Println("Getting top stories")
-	var topStories []int
-	err := handleRequest("GET", "topstories.json", nil, &topStories)
+// url returns the URL of an endpoint, which is relative to the base URL unless it's already absolute.
+func (c *Client) url(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	return c.baseURL + endpoint
+}
+
+// fetchOnce makes a single request to the Hacker News API and returns the body of a successful response.
+func (c *Client) fetchOnce(ctx context.Context, method string, endpoint string, headers map[string]string) ([]byte, error) {
+	response, err := c.restyClient.R().
+		SetContext(ctx).
+		SetHeaders(headers).
+		Execute(method, c.url(endpoint))
+
 	if err != nil {
+		c.logger.Printf("Request to %s failed: %v", c.url(endpoint), err)
 		return nil, err
 	}
-	return topStories, nil
+	c.logger.Printf("Request to %s returned %d", c.url(endpoint), response.StatusCode())
+	if response.IsError() {
+		return nil, &StatusError{
+			StatusCode: response.StatusCode(),
+			Body:       response.String(),
+			RetryAfter: parseRetryAfter(response.Header().Get("Retry-After"), time.Now()),
+		}
+	}
+	return response.Body(), nil
+}
+
+// GetTopStories returns the IDs of the stories on the front page.
+func (c *Client) GetTopStories(ctx context.Context) ([]int, error) {
+	return c.GetFeed(ctx, FeedTop)
 }
 
+// Item is a story, comment, job, poll or poll option. Which fields are set depends on the type, see item.go.
 type Item struct {
-	Id       int    `json:"id"`
-	Type     string `json:"type"`
-	By       string `json:"by"`
-	Time     int    `json:"time"`
-	Title    string `json:"title"`
-	Text     string `json:"text"`
-	Url      string `json:"url"`
-	Score    int    `json:"score"`
-	Kids     []int  `json:"kids"`
-	Comments []Item `json:"comments"`
-}
-
-func GetItem(itemId int) (Item, error) {
-	log.Logger.Printf("Getting item %d", itemId)
+	Id          int    `json:"id"`
+	Type        string `json:"type"`
+	By          string `json:"by"`
+	Time        int    `json:"time"`
+	Title       string `json:"title"`
+	Text        string `json:"text"`
+	Url         string `json:"url"`
+	Score       int    `json:"score"`
+	Kids        []int  `json:"kids"`
+	Deleted     bool   `json:"deleted"`
+	Dead        bool   `json:"dead"`
+	Parent      int    `json:"parent"`      // The comment or story a comment replies to
+	Poll        int    `json:"poll"`        // The poll a poll option belongs to
+	Parts       []int  `json:"parts"`       // A poll's options, in display order
+	Descendants int    `json:"descendants"` // The total number of comments on a story or poll
+	Comments    []Item `json:"comments"`
+	Options     []Item `json:"options"` // A poll's options, once loaded from Parts
+
+	Unavailable bool `json:"-"` // Set on placeholders for items we couldn't fetch
+}
+
+func (c *Client) GetItem(ctx context.Context, itemId int) (Item, error) {
+	c.logger.Printf("Getting item %d", itemId)
 	var item Item
-	err := handleRequest("GET", fmt.Sprintf("item/%d.json", itemId), nil, &item)
+	err := c.handleRequest(ctx, "GET", fmt.Sprintf("item/%d.json", itemId), nil, &item)
 	if err != nil {
 		return Item{}, err
 	}
 
+	c.index.Add(item)
 	return item, nil
 }
 
-func GetItemWithComments(itemId, maxComments int) (Item, error) {
-	log.Logger.Printf("Getting item with comments %d", itemId)
+// GetItemWithComments fetches an item along with up to maxComments of its direct replies. Replies which have no text
+// or have been removed ([dead], [flagged], ...) are skipped, and those which fail to load are kept as Unavailable
+// placeholders.
+func (c *Client) GetItemWithComments(ctx context.Context, itemId, maxComments int) (Item, error) {
+	c.logger.Printf("Getting item with comments %d", itemId)
 	var item Item
-	err := handleRequest("GET", fmt.Sprintf("item/%d.json", itemId), nil, &item)
+	err := c.handleRequest(ctx, "GET", fmt.Sprintf("item/%d.json", itemId), nil, &item)
 	if err != nil {
 		return Item{}, err
 	}
+	c.index.Add(item)
 
-	// Gather details of the comments
-	for _, commentId := range item.Kids {
-		if len(item.Comments) >= maxComments {
-			break
-		}
-		log.Logger.Printf("Getting comment %d", commentId)
-		var comment Item
-		err := handleRequest("GET", fmt.Sprintf("item/%d.json", commentId), nil, &comment)
-		if err != nil {
-			return Item{}, err
-		}
+	// Gather details of the comments, one batch at a time until we have enough of them
+	kids := item.Kids
+	for len(kids) > 0 && len(item.Comments) < maxComments {
+		batch := kids[:min(maxComments-len(item.Comments), len(kids))]
+		kids = kids[len(batch):]
 
-		if comment.Text == "" {
-			// Skip comments with no text
-			continue
+		comments, err := c.GetItems(ctx, batch)
+		if err != nil {
+			c.logger.Printf("Error getting comments of %d: %v", itemId, err)
 		}
-
-		if strings.HasPrefix(comment.Text, "[") {
-			// Remove comments that are [dupe] or [dead] or [flagged]
-			continue
+		for _, comment := range comments {
+			if isReadableComment(comment) {
+				item.Comments = append(item.Comments, comment)
+			}
 		}
-
-		item.Comments = append(item.Comments, comment)
 	}
 
 	return item, nil
@@ -129,31 +395,41 @@ type TopMenuResponse struct {
 }
 
 // Returns the top menu response with the top stories as items with only their IDs
-func GetTopMenuResponse() (TopMenuResponse, error) {
-	var topMenuResponse TopMenuResponse
-	topStories, err := GetTopStories()
-	if err != nil {
-		return TopMenuResponse{}, err
-	}
-
-	topMenuResponse.Items = make([]Item, 0)
-	for _, storyId := range topStories {
-		topMenuResponse.Items = append(topMenuResponse.Items, Item{Id: storyId})
-	}
-	return topMenuResponse, nil
-
+func (c *Client) GetTopMenuResponse(ctx context.Context) (TopMenuResponse, error) {
+	return c.GetFeedMenuResponse(ctx, FeedTop)
 }
 
-func (t TopMenuResponse) EnrichItems(pageSize, page int) {
-	pageStories := t.Items[pageSize*(page-1) : pageSize*page]
+// EnrichItems fetches the details of the items on the given page which have only their IDs. Items which fail to
+// load are left as Unavailable placeholders and reported in the returned error, except those given up on because ctx
+// was cancelled, which keep only their IDs. t's items are changed in place, so callers shouldn't share them with
+// anything else while this runs.
+func (t TopMenuResponse) EnrichItems(ctx context.Context, c *Client, pageSize, page int) error {
+	start := min(pageSize*(page-1), len(t.Items))
+	end := min(pageSize*page, len(t.Items))
+	pageStories := t.Items[start:end]
+
+	var indexes, itemIds []int
 	for i, item := range pageStories {
 		if item.Type == "" {
-			item, err := GetItem(item.Id)
-			if err != nil {
-				log.Logger.Printf("Error getting item %d: %v", item.Id, err)
-				continue
+			indexes = append(indexes, i)
+			itemIds = append(itemIds, item.Id)
+		}
+	}
+
+	items, err := c.GetItems(ctx, itemIds)
+	cancelled := map[int]bool{}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			var itemErr *ItemError
+			if errors.As(err, &itemErr) && errors.Is(itemErr, context.Canceled) {
+				cancelled[itemErr.Id] = true
 			}
-			pageStories[i] = item
 		}
 	}
+	for i, item := range items {
+		if !cancelled[item.Id] {
+			pageStories[indexes[i]] = item
+		}
+	}
+	return err
 }
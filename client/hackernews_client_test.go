@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc lets a function stand in for an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// newTestClient returns a Client whose requests are answered from bodies, keyed by request path.
+func newTestClient(t *testing.T, bodies map[string]string) *Client {
+	t.Helper()
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, ok := bodies[r.URL.Path]
+		status := http.StatusOK
+		if !ok {
+			status, body = http.StatusNotFound, "null"
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})
+	return New(
+		WithBaseURL("http://hn.test/v0"),
+		WithTransport(transport),
+		WithLogger(log.New(io.Discard, "", 0)),
+	)
+}
+
+func TestClient_GetTopMenuResponse(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/topstories.json": "[3, 1, 2]",
+		"/v0/item/3.json":     `{"id": 3, "type": "story", "title": "three"}`,
+		"/v0/item/1.json":     `{"id": 1, "type": "story", "title": "one"}`,
+	})
+
+	got, err := c.GetTopMenuResponse(context.Background())
+	if err != nil {
+		t.Fatalf("GetTopMenuResponse() error = %v", err)
+	}
+	got.EnrichItems(context.Background(), c, 2, 1)
+
+	want := []Item{{Id: 3, Type: "story", Title: "three"}, {Id: 1, Type: "story", Title: "one"}, {Id: 2}}
+	if !reflect.DeepEqual(got.Items, want) {
+		t.Errorf("GetTopMenuResponse() = %v, want %v", got.Items, want)
+	}
+}
+
+func TestTopMenuResponse_EnrichItemsCancelled(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/item/1.json": `{"id": 1, "type": "story", "title": "one"}`,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got := TopMenuResponse{Items: []Item{{Id: 1}, {Id: 2}}}
+	if err := got.EnrichItems(ctx, c, 2, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("EnrichItems() error = %v, want context.Canceled", err)
+	}
+	if want := []Item{{Id: 1}, {Id: 2}}; !reflect.DeepEqual(got.Items, want) {
+		t.Errorf("EnrichItems() = %v, want the items left with only their IDs", got.Items)
+	}
+}
+
+func TestClient_GetItemWithComments(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/item/1.json": `{"id": 1, "type": "story", "kids": [2, 3, 4, 5]}`,
+		"/v0/item/2.json": `{"id": 2, "type": "comment", "text": "first"}`,
+		"/v0/item/3.json": `{"id": 3, "type": "comment", "text": "[flagged]", "dead": true}`,
+		"/v0/item/4.json": `{"id": 4, "type": "comment", "text": "second"}`,
+		"/v0/item/5.json": `{"id": 5, "type": "comment", "text": "third"}`,
+	})
+
+	got, err := c.GetItemWithComments(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatalf("GetItemWithComments() error = %v", err)
+	}
+	var texts []string
+	for _, comment := range got.Comments {
+		texts = append(texts, comment.Text)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("GetItemWithComments() comments = %v, want %v", texts, want)
+	}
+}
+
+func TestClient_handleRequestError(t *testing.T) {
+	c := newTestClient(t, map[string]string{})
+	if _, err := c.GetItem(context.Background(), 42); err == nil {
+		t.Error("GetItem() expected an error for a 404 response")
+	}
+}
+
+func TestClient_GetUser(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/user/pg.json":     `{"id": "pg", "created": 1160418092, "karma": 155111, "submitted": [3, 2, 1]}`,
+		"/v0/user/nobody.json": `null`,
+	})
+
+	got, err := c.GetUser(context.Background(), "pg")
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	want := User{Id: "pg", Created: 1160418092, Karma: 155111, Submitted: []int{3, 2, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetUser() = %v, want %v", got, want)
+	}
+
+	if _, err := c.GetUser(context.Background(), "nobody"); err == nil {
+		t.Error("GetUser() expected an error for a user that doesn't exist")
+	}
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"cmp"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Fields of an item which are indexed, and how much a match in each counts towards a result's rank.
+const (
+	fieldTitle indexField = 1 << iota
+	fieldText
+	fieldAuthor
+	fieldDomain
+)
+
+type indexField uint8
+
+var fieldWeights = map[indexField]int{fieldTitle: 3, fieldText: 1, fieldAuthor: 2, fieldDomain: 2}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Index is an in-memory full-text index of items, searched by title, text, author and URL domain. It's safe for
+// concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	items    map[int]Item                  // The indexed items, without their comments or poll options
+	postings map[string]map[int]indexField // Which fields of which items each term appears in
+	terms    map[int][]string              // The terms each item was indexed under, so it can be reindexed
+}
+
+// SearchResult is an item matching a search.
+type SearchResult struct {
+	Item  Item
+	Terms []string // The query's terms, lower case, for highlighting where the item matched
+	Rank  int      // How well the item matched, higher is better
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{items: map[int]Item{}, postings: map[string]map[int]indexField{}, terms: map[int][]string{}}
+}
+
+// Add indexes an item, replacing whatever was indexed for it before. Placeholders and removed items aren't indexed.
+func (x *Index) Add(item Item) {
+	if item.Unavailable || item.Removed() || item.Type == "" {
+		return
+	}
+	item.Comments, item.Options = nil, nil
+
+	fields := map[string]indexField{}
+	for field, text := range map[indexField]string{
+		fieldTitle:  item.Title,
+		fieldText:   item.Text,
+		fieldAuthor: item.By,
+		fieldDomain: item.Domain(),
+	} {
+		for _, term := range tokenize(text) {
+			fields[term] |= field
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(item.Id)
+	x.items[item.Id] = item
+	for term, field := range fields {
+		if x.postings[term] == nil {
+			x.postings[term] = map[int]indexField{}
+		}
+		x.postings[term][item.Id] = field
+		x.terms[item.Id] = append(x.terms[item.Id], term)
+	}
+}
+
+// remove drops an item from the index. The caller must hold the lock.
+func (x *Index) remove(itemId int) {
+	for _, term := range x.terms[itemId] {
+		delete(x.postings[term], itemId)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.terms, itemId)
+	delete(x.items, itemId)
+}
+
+// Len returns the number of items in the index.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.items)
+}
+
+// Search returns up to limit items matching every word of the query, best matches first. Words match terms they're
+// a prefix of, so results come up while the last word is still being typed. Ties go to the higher scoring item.
+func (x *Index) Search(query string, limit int) []SearchResult {
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	var ranks map[int]int
+	for _, queryTerm := range queryTerms {
+		// Gather the fields of each item this word matches
+		matches := map[int]indexField{}
+		for term, postings := range x.postings {
+			if !strings.HasPrefix(term, queryTerm) {
+				continue
+			}
+			for itemId, field := range postings {
+				matches[itemId] |= field
+			}
+		}
+
+		next := map[int]int{}
+		for itemId, fields := range matches {
+			if _, ok := ranks[itemId]; ranks != nil && !ok {
+				continue // Missing an earlier word
+			}
+			next[itemId] = ranks[itemId]
+			for field, weight := range fieldWeights {
+				if fields&field != 0 {
+					next[itemId] += weight
+				}
+			}
+		}
+		ranks = next
+	}
+
+	results := make([]SearchResult, 0, len(ranks))
+	for itemId, rank := range ranks {
+		results = append(results, SearchResult{Item: x.items[itemId], Terms: queryTerms, Rank: rank})
+	}
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(b.Item.Score, a.Item.Score), cmp.Compare(b.Item.Time, a.Item.Time))
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// tokenize splits text, which may be HTML, into lower case words.
+func tokenize(text string) []string {
+	text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " "))
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// WithIndex indexes the items the client fetches into the given index, rather than one of its own.
+func WithIndex(x *Index) Option {
+	return func(c *Client) {
+		c.index = x
+	}
+}
+
+// Search returns up to limit of the items the client has fetched which match the query, best matches first.
+func (c *Client) Search(query string, limit int) []SearchResult {
+	return c.index.Search(query, limit)
+}
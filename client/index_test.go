@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestIndex_Search(t *testing.T) {
+	x := NewIndex()
+	x.Add(Item{Id: 1, Type: TypeStory, By: "pg", Title: "Launch HN: Go tooling", Url: "https://www.example.com/go", Score: 10})
+	x.Add(Item{Id: 2, Type: TypeComment, By: "rsc", Text: "I&#x27;d use <i>Go</i> modules for this", Score: 0})
+	x.Add(Item{Id: 3, Type: TypeStory, By: "dang", Title: "Rust tooling", Score: 50})
+	x.Add(Item{Id: 4, Type: TypeComment, By: "gone", Text: "go go go", Dead: true})
+	x.Add(Item{Id: 5})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{name: "TestTitleRanksAboveText", query: "go", want: []int{1, 2}},
+		{name: "TestEveryWordMustMatch", query: "go tooling", want: []int{1}},
+		{name: "TestPrefix", query: "tool", want: []int{3, 1}},
+		{name: "TestAuthor", query: "RSC", want: []int{2}},
+		{name: "TestDomain", query: "example.com", want: []int{1}},
+		{name: "TestHtmlEntities", query: "i'd", want: []int{2}},
+		{name: "TestNoMatch", query: "python", want: []int{}},
+		{name: "TestEmptyQuery", query: "  ", want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []int{}
+			for _, result := range x.Search(tt.query, 10) {
+				got = append(got, result.Item.Id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndex_AddReplaces(t *testing.T) {
+	x := NewIndex()
+	x.Add(Item{Id: 1, Type: TypeComment, Text: "before"})
+	x.Add(Item{Id: 1, Type: TypeComment, Text: "after"})
+	if got := x.Search("before", 0); len(got) != 0 {
+		t.Errorf("Search() = %v, want the old text forgotten", got)
+	}
+	if got := x.Search("after", 0); len(got) != 1 || x.Len() != 1 {
+		t.Errorf("Search() = %v, want the item indexed once under its new text", got)
+	}
+}
+
+func TestClient_SearchIndexesFetchedItems(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/item/1.json": `{"id": 1, "type": "story", "title": "Show HN: a terminal reader", "kids": [2]}`,
+		"/v0/item/2.json": `{"id": 2, "type": "comment", "text": "Nice terminal colours"}`,
+	})
+	if _, err := c.GetItemTree(context.Background(), 1, TreeOptions{}); err != nil {
+		t.Fatalf("GetItemTree() error = %v", err)
+	}
+	got := c.Search("terminal", 10)
+	if len(got) != 2 || got[0].Item.Id != 1 || got[1].Item.Id != 2 {
+		t.Errorf("Search() = %+v, want the story then its comment", got)
+	}
+	if !reflect.DeepEqual(got[0].Terms, []string{"terminal"}) {
+		t.Errorf("Search() terms = %v, want [terminal]", got[0].Terms)
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// The types of item.
+const (
+	TypeStory   = "story"
+	TypeComment = "comment"
+	TypeJob     = "job"
+	TypePoll    = "poll"
+	TypePollOpt = "pollopt"
+)
+
+// IsStory reports whether the item is a story, including Ask and Show HN posts.
+func (i Item) IsStory() bool {
+	return i.Type == TypeStory
+}
+
+// IsComment reports whether the item is a comment. Its Parent is the comment or story it replies to.
+func (i Item) IsComment() bool {
+	return i.Type == TypeComment
+}
+
+// IsJob reports whether the item is a job posting. Jobs have no comments.
+func (i Item) IsJob() bool {
+	return i.Type == TypeJob
+}
+
+// IsPoll reports whether the item is a poll. Its Parts are the IDs of its options.
+func (i Item) IsPoll() bool {
+	return i.Type == TypePoll
+}
+
+// IsPollOpt reports whether the item is a poll option. Its Poll is the poll it belongs to, and its Score the votes for
+// it.
+func (i Item) IsPollOpt() bool {
+	return i.Type == TypePollOpt
+}
+
+// Removed reports whether the item was deleted by its author or killed by moderators or flags.
+func (i Item) Removed() bool {
+	return i.Deleted || i.Dead
+}
+
+// CommentCount returns how many comments there are beneath the item. Only stories and polls count every comment in
+// their thread, so for comments this is just the direct replies.
+func (i Item) CommentCount() int {
+	if i.IsComment() {
+		return len(i.Kids)
+	}
+	return i.Descendants
+}
+
+// Incomplete reports whether the item, or any comment loaded beneath it, is a placeholder for one which couldn't be
+// fetched.
+func (i Item) Incomplete() bool {
+	if i.Unavailable {
+		return true
+	}
+	for _, comment := range i.Comments {
+		if comment.Incomplete() {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the item with the given ID from the item's comment tree, the item itself included.
+func (i Item) Find(itemId int) (Item, bool) {
+	if i.Id == itemId {
+		return i, true
+	}
+	for _, comment := range i.Comments {
+		if found, ok := comment.Find(itemId); ok {
+			return found, true
+		}
+	}
+	return Item{}, false
+}
+
+// Domain returns the host name of the item's URL without any "www." prefix, or "" if it has no URL.
+func (i Item) Domain() string {
+	if i.Url == "" {
+		return ""
+	}
+	u, err := url.Parse(i.Url)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// DiscussionUrl returns the URL of the item's page on Hacker News.
+func (i Item) DiscussionUrl() string {
+	return fmt.Sprintf("https://news.ycombinator.com/item?id=%d", i.Id)
+}
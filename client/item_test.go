@@ -0,0 +1,118 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestItem_decode(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want Item
+	}{
+		{
+			name: "TestStory",
+			json: `{"id": 1, "type": "story", "by": "pg", "kids": [2], "descendants": 5, "score": 10, "title": "Y", "url": "http://y.com"}`,
+			want: Item{Id: 1, Type: TypeStory, By: "pg", Kids: []int{2}, Descendants: 5, Score: 10, Title: "Y", Url: "http://y.com"},
+		},
+		{
+			name: "TestDeletedComment",
+			json: `{"id": 2, "type": "comment", "parent": 1, "deleted": true}`,
+			want: Item{Id: 2, Type: TypeComment, Parent: 1, Deleted: true},
+		},
+		{
+			name: "TestPoll",
+			json: `{"id": 3, "type": "poll", "parts": [4, 5], "descendants": 0}`,
+			want: Item{Id: 3, Type: TypePoll, Parts: []int{4, 5}},
+		},
+		{
+			name: "TestPollOpt",
+			json: `{"id": 4, "type": "pollopt", "poll": 3, "score": 7, "text": "Yes"}`,
+			want: Item{Id: 4, Type: TypePollOpt, Poll: 3, Score: 7, Text: "Yes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Item
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItem_CommentCount(t *testing.T) {
+	tests := []struct {
+		name string
+		item Item
+		want int
+	}{
+		{name: "TestStory", item: Item{Type: TypeStory, Kids: []int{1, 2}, Descendants: 9}, want: 9},
+		{name: "TestPoll", item: Item{Type: TypePoll, Descendants: 3}, want: 3},
+		{name: "TestComment", item: Item{Type: TypeComment, Kids: []int{1, 2}}, want: 2},
+		{name: "TestJob", item: Item{Type: TypeJob}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.CommentCount(); got != tt.want {
+				t.Errorf("CommentCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItem_Incomplete(t *testing.T) {
+	tests := []struct {
+		name string
+		item Item
+		want bool
+	}{
+		{name: "TestComplete", item: Item{Id: 1, Comments: []Item{{Id: 2, Comments: []Item{{Id: 3}}}}}, want: false},
+		{name: "TestUnavailable", item: Item{Id: 1, Unavailable: true}, want: true},
+		{name: "TestUnavailableReply", item: Item{Id: 1, Comments: []Item{{Id: 2, Comments: []Item{{Id: 3, Unavailable: true}}}}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.Incomplete(); got != tt.want {
+				t.Errorf("Incomplete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItem_Find(t *testing.T) {
+	reply := Item{Id: 3, Text: "reply"}
+	item := Item{Id: 1, Comments: []Item{{Id: 2}, {Id: 4, Comments: []Item{reply}}}}
+	if got, ok := item.Find(3); !ok || !reflect.DeepEqual(got, reply) {
+		t.Errorf("Find(3) = %+v, %v, want the reply", got, ok)
+	}
+	if got, ok := item.Find(1); !ok || got.Id != 1 {
+		t.Errorf("Find(1) = %+v, %v, want the item itself", got, ok)
+	}
+	if _, ok := item.Find(5); ok {
+		t.Errorf("Find(5) found an item which isn't in the tree")
+	}
+}
+
+func TestItem_Domain(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://www.example.com/a?b=c", want: "example.com"},
+		{url: "http://blog.example.co.uk:8080/", want: "blog.example.co.uk"},
+		{url: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := (Item{Url: tt.url}).Domain(); got != tt.want {
+				t.Errorf("Domain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
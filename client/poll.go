@@ -0,0 +1,19 @@
+package client
+
+import "context"
+
+// GetPollOptions fetches the options of a poll, in the order they're listed. Options which fail to load are kept as
+// Unavailable placeholders.
+func (c *Client) GetPollOptions(ctx context.Context, poll Item) ([]Item, error) {
+	c.logger.Printf("Getting %d options of poll %d", len(poll.Parts), poll.Id)
+	return c.GetItems(ctx, poll.Parts)
+}
+
+// PollVotes returns the total votes across a poll's options.
+func PollVotes(options []Item) int {
+	total := 0
+	for _, option := range options {
+		total += option.Score
+	}
+	return total
+}
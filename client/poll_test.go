@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestClient_GetItemTreePoll(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/item/1.json": `{"id": 1, "type": "poll", "parts": [3, 2], "kids": [4]}`,
+		"/v0/item/2.json": `{"id": 2, "type": "pollopt", "poll": 1, "score": 5, "text": "No"}`,
+		"/v0/item/3.json": `{"id": 3, "type": "pollopt", "poll": 1, "score": 15, "text": "Yes"}`,
+		"/v0/item/4.json": `{"id": 4, "type": "comment", "text": "voted"}`,
+	})
+
+	got, err := c.GetItemTree(context.Background(), 1, TreeOptions{})
+	if err != nil {
+		t.Fatalf("GetItemTree() error = %v", err)
+	}
+	want := []Item{
+		{Id: 3, Type: TypePollOpt, Poll: 1, Score: 15, Text: "Yes"},
+		{Id: 2, Type: TypePollOpt, Poll: 1, Score: 5, Text: "No"},
+	}
+	if !reflect.DeepEqual(got.Options, want) {
+		t.Errorf("GetItemTree() options = %+v, want %+v", got.Options, want)
+	}
+	if len(got.Comments) != 1 {
+		t.Errorf("GetItemTree() comments = %+v, want the poll's comment", got.Comments)
+	}
+	if votes := PollVotes(got.Options); votes != 20 {
+		t.Errorf("PollVotes() = %d, want 20", votes)
+	}
+}
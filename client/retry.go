@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries       = 3
+	defaultRetryBaseDelay   = 250 * time.Millisecond
+	defaultRetryMaxDelay    = 4 * time.Second
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+	maxRetryAfter           = time.Minute // The longest we'll wait when the API asks us to back off
+	retryWindow             = time.Minute // How far back Stats counts retries
+)
+
+// ErrCircuitOpen is returned without making a request while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("too many failed requests, pausing requests to the API")
+
+// StatusError is returned for responses with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // How long the API asked us to wait before retrying, if it did
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("error: %d %s", e.StatusCode, e.Body)
+}
+
+// retryable reports whether a failed request is worth retrying: transport errors, rate limiting and server errors.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return !errors.Is(err, ErrCircuitOpen)
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return min(time.Duration(max(0, seconds))*time.Second, maxRetryAfter)
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return min(max(0, t.Sub(now)), maxRetryAfter)
+	}
+	return 0
+}
+
+// retryPolicy describes how failed GET requests are retried.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+// backoff returns how long to wait before the given retry, counting from 0. The delay grows exponentially with full
+// jitter, unless the API told us how long to wait.
+func (p retryPolicy) backoff(retry int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	ceiling := min(p.baseDelay<<retry, p.maxDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+// sleepContext waits for d, or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithRetries sets how many times failed GET requests are retried, and the bounds of the delay between retries.
+func WithRetries(maxRetries int, baseDelay, maxDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retryPolicy = retryPolicy{maxRetries: maxRetries, baseDelay: baseDelay, maxDelay: maxDelay}
+	}
+}
+
+// WithCircuitBreaker stops requests being made to an API for cooldown after threshold requests to it in a row fail.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.api.breaker = newCircuitBreaker(threshold, cooldown)
+		c.search.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+}
+
+// BreakerState is the state of the client's circuit breaker.
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Requests are made as normal
+	BreakerOpen                         // Requests fail straight away with ErrCircuitOpen
+	BreakerHalfOpen                     // A single request is let through to see if the API has recovered
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// circuitBreaker stops us hammering the API after repeated failures.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int       // Failures in a row
+	openedAt time.Time // When the breaker last opened
+	probing  bool      // Whether the half-open probe request is in flight
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow returns ErrCircuitOpen if a request shouldn't be made right now.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.state = BreakerHalfOpen
+	}
+	switch {
+	case b.state == BreakerOpen, b.state == BreakerHalfOpen && b.probing:
+		return ErrCircuitOpen
+	case b.state == BreakerHalfOpen:
+		b.probing = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of a request, returning its new state.
+func (b *circuitBreaker) record(success bool) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.state = BreakerClosed
+		b.failures = 0
+		return b.state
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+	return b.state
+}
+
+// release gives up on a request allow let through without recording its outcome, e.g. because it was cancelled.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// currentState returns the breaker's state.
+func (b *circuitBreaker) currentState() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// upstream is an API the client makes requests to. Each has its own circuit breaker and count of retries, so trouble
+// with one doesn't hold up requests to the other.
+type upstream struct {
+	breaker *circuitBreaker
+	now     func() time.Time
+
+	mu      sync.Mutex
+	retries []time.Time // When requests were retried, within the last retryWindow
+}
+
+func newUpstream(threshold int, cooldown time.Duration) *upstream {
+	return &upstream{breaker: newCircuitBreaker(threshold, cooldown), now: time.Now}
+}
+
+// retried notes that a request to the upstream API is being retried.
+func (u *upstream) retried() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.retries = append(u.recentRetries(), u.now())
+}
+
+// recentRetries drops the retries from before the window. The caller must hold u.mu.
+func (u *upstream) recentRetries() []time.Time {
+	cutoff := u.now().Add(-retryWindow)
+	i := 0
+	for i < len(u.retries) && !u.retries[i].After(cutoff) {
+		i++
+	}
+	u.retries = u.retries[i:]
+	return u.retries
+}
+
+// stats returns how requests to the upstream API have been going lately.
+func (u *upstream) stats() Stats {
+	u.mu.Lock()
+	retries := len(u.recentRetries())
+	u.mu.Unlock()
+	return Stats{Retries: retries, Breaker: u.breaker.currentState()}
+}
+
+// Stats describes how the client's requests have been going.
+type Stats struct {
+	Retries int          // How many times requests have been retried in the last minute
+	Breaker BreakerState // The state of the circuit breaker
+}
+
+// Stats returns how the client's requests to the Hacker News API have been going.
+func (c *Client) Stats() Stats {
+	return c.api.stats()
+}
+
+// SearchStats returns how the client's requests to the HN Search API have been going.
+func (c *Client) SearchStats() Stats {
+	return c.search.stats()
+}
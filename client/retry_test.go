@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFlakyClient returns a Client whose requests get the given statuses in turn, and a record of how long it slept
+// between retries.
+func newFlakyClient(t *testing.T, statuses []int, headers http.Header, opts ...Option) (*Client, *[]time.Duration, *int) {
+	t.Helper()
+	requests := 0
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		status := statuses[min(requests, len(statuses)-1)]
+		requests++
+		return &http.Response{
+			StatusCode: status,
+			Header:     headers,
+			Body:       io.NopCloser(strings.NewReader(`{"id": 1}`)),
+			Request:    r,
+		}, nil
+	})
+	var sleeps []time.Duration
+	opts = append([]Option{
+		WithBaseURL("http://hn.test/v0"),
+		WithTransport(transport),
+		WithLogger(log.New(io.Discard, "", 0)),
+	}, opts...)
+	c := New(opts...)
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return c, &sleeps, &requests
+}
+
+func TestClient_fetchRetries(t *testing.T) {
+	c, sleeps, requests := newFlakyClient(t, []int{503, 502, 200}, nil, WithRetries(3, 100*time.Millisecond, time.Second))
+
+	if _, err := c.GetItem(context.Background(), 1); err != nil {
+		t.Fatalf("GetItem() error = %v", err)
+	}
+	if *requests != 3 || len(*sleeps) != 2 || c.Stats().Retries != 2 {
+		t.Errorf("GetItem() made %d requests and %d retries, want 3 and 2", *requests, c.Stats().Retries)
+	}
+	for i, d := range *sleeps {
+		if ceiling := 100 * time.Millisecond << i; d <= 0 || d > ceiling {
+			t.Errorf("retry %d slept %v, want up to %v", i, d, ceiling)
+		}
+	}
+}
+
+func TestClient_StatsRecentRetries(t *testing.T) {
+	// Two brief blips half a minute apart
+	c, _, _ := newFlakyClient(t, []int{503, 200, 503, 200}, nil)
+	now := time.Unix(1700000000, 0)
+	c.api.now = func() time.Time { return now }
+
+	c.GetItem(context.Background(), 1)
+	now = now.Add(30 * time.Second)
+	c.GetItem(context.Background(), 2)
+	if got := c.Stats().Retries; got != 2 {
+		t.Errorf("Stats().Retries = %d after two retries in a minute, want 2", got)
+	}
+	now = now.Add(45 * time.Second)
+	if got := c.Stats().Retries; got != 1 {
+		t.Errorf("Stats().Retries = %d once the first retry is over a minute old, want 1", got)
+	}
+	now = now.Add(time.Minute)
+	if got := c.Stats().Retries; got != 0 {
+		t.Errorf("Stats().Retries = %d once the API has been healthy for a minute, want 0", got)
+	}
+}
+
+func TestClient_fetchRetryAfter(t *testing.T) {
+	headers := http.Header{"Retry-After": []string{"7"}}
+	c, sleeps, _ := newFlakyClient(t, []int{429, 200}, headers)
+
+	if _, err := c.GetItem(context.Background(), 1); err != nil {
+		t.Fatalf("GetItem() error = %v", err)
+	}
+	if want := []time.Duration{7 * time.Second}; !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("GetItem() slept %v, want %v", *sleeps, want)
+	}
+}
+
+func TestClient_fetchGivesUp(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int
+	}{
+		{name: "TestNotRetryable", statuses: []int{404}, wantRequests: 1},
+		{name: "TestOutOfRetries", statuses: []int{500}, wantRequests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _, requests := newFlakyClient(t, tt.statuses, nil, WithRetries(2, time.Millisecond, time.Millisecond))
+			var statusErr *StatusError
+			if _, err := c.GetItem(context.Background(), 1); !errors.As(err, &statusErr) || statusErr.StatusCode != tt.statuses[0] {
+				t.Errorf("GetItem() error = %v, want a %d StatusError", err, tt.statuses[0])
+			}
+			if *requests != tt.wantRequests {
+				t.Errorf("GetItem() made %d requests, want %d", *requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClient_fetchCircuitBreaker(t *testing.T) {
+	c, _, requests := newFlakyClient(t, []int{500, 500, 500, 200}, nil,
+		WithRetries(0, 0, 0), WithCircuitBreaker(3, time.Minute))
+	now := time.Unix(1700000000, 0)
+	c.api.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		c.GetItem(context.Background(), 1)
+	}
+	if state := c.Stats().Breaker; state != BreakerOpen {
+		t.Fatalf("breaker is %v after 3 failures, want open", state)
+	}
+	if _, err := c.GetItem(context.Background(), 1); !errors.Is(err, ErrCircuitOpen) || *requests != 3 {
+		t.Errorf("GetItem() with the breaker open = %v after %d requests, want ErrCircuitOpen after 3", err, *requests)
+	}
+
+	// Once the cooldown is over a request is let through, and closes the breaker when it succeeds
+	now = now.Add(time.Minute)
+	if _, err := c.GetItem(context.Background(), 1); err != nil {
+		t.Errorf("GetItem() after the cooldown error = %v", err)
+	}
+	if state := c.Stats().Breaker; state != BreakerClosed {
+		t.Errorf("breaker is %v after a successful probe, want closed", state)
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{name: "TestSeconds", header: "30", want: 30 * time.Second},
+		{name: "TestDate", header: "Mon, 01 Jan 2024 12:00:10 GMT", want: 10 * time.Second},
+		{name: "TestCapped", header: "3600", want: maxRetryAfter},
+		{name: "TestEmpty", header: "", want: 0},
+		{name: "TestGarbage", header: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.header, now); got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
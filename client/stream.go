@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// StreamEvent is a change to a location in the API, as streamed by Firebase's REST streaming protocol.
+type StreamEvent struct {
+	Type string          // "put" or "patch" for changes, or "cancel" and "auth_revoked" when the stream ends
+	Path string          // Where the change is, relative to the subscribed location, e.g. "/" or "/score"
+	Data json.RawMessage // The new data at Path for a put, or the fields to update at Path for a patch
+}
+
+// ApplyTo returns item with the change applied. Only changes to the item itself or its top level fields are supported.
+func (e StreamEvent) ApplyTo(item Item) (Item, error) {
+	field := strings.Trim(e.Path, "/")
+	if strings.Contains(field, "/") {
+		return item, fmt.Errorf("can't apply a change to %s", e.Path)
+	}
+
+	data := e.Data
+	if field != "" {
+		// Changes to a single field are merged into the item as if they were a patch of the item
+		data = json.RawMessage(fmt.Sprintf("{%q: %s}", field, e.Data))
+	} else if e.Type == "put" {
+		// The whole item was replaced
+		item = Item{Comments: item.Comments, Options: item.Options}
+	}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return item, err
+	}
+	return item, nil
+}
+
+// streamPayload is the data of a put or patch event.
+type streamPayload struct {
+	Path string          `json:"path"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Subscribe streams changes to the data at endpoint, e.g. "item/8863.json", until ctx is cancelled. Every event
+// besides keep-alives is sent on the returned channel, which is closed when the stream ends. The first event is a put
+// of the whole location.
+func (c *Client) Subscribe(ctx context.Context, endpoint string) (<-chan StreamEvent, error) {
+	if c.offline {
+		return nil, fmt.Errorf("%s: %w", endpoint, ErrUnavailable)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.restyClient.Header {
+		request.Header[key] = values
+	}
+	request.Header.Set("Accept", "text/event-stream")
+
+	// The stream stays open indefinitely, so it can't use the client's request timeout
+	httpClient := &http.Client{Transport: c.restyClient.GetClient().Transport}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		c.logger.Printf("Subscription to %s%s failed: %v", c.baseURL, endpoint, err)
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		return nil, &StatusError{StatusCode: response.StatusCode}
+	}
+	c.logger.Printf("Subscribed to %s%s", c.baseURL, endpoint)
+
+	events := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+		defer response.Body.Close()
+		err := readStreamEvents(response, func(event StreamEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		c.logger.Printf("Subscription to %s%s ended: %v", c.baseURL, endpoint, err)
+	}()
+	return events, nil
+}
+
+// SubscribeItem streams changes to an item.
+func (c *Client) SubscribeItem(ctx context.Context, itemId int) (<-chan StreamEvent, error) {
+	return c.Subscribe(ctx, fmt.Sprintf("item/%d.json", itemId))
+}
+
+// SubscribeFeed streams changes to the list of stories on a feed.
+func (c *Client) SubscribeFeed(ctx context.Context, feed Feed) (<-chan StreamEvent, error) {
+	return c.Subscribe(ctx, string(feed)+".json")
+}
+
+// readStreamEvents parses server-sent events from a response, passing each to send until send returns false or the
+// stream ends.
+func readStreamEvents(response *http.Response, send func(StreamEvent) bool) error {
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // Whole feeds and items can arrive on a single line
+
+	var eventType string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line dispatches the event we've gathered
+			event := StreamEvent{Type: eventType}
+			payload := strings.Join(data, "\n")
+			eventType, data = "", nil
+			switch event.Type {
+			case "", "keep-alive":
+				continue
+			case "put", "patch":
+				var p streamPayload
+				if err := json.Unmarshal([]byte(payload), &p); err != nil {
+					return fmt.Errorf("bad %s event: %w", event.Type, err)
+				}
+				event.Path, event.Data = p.Path, p.Data
+			}
+			if !send(event) {
+				return nil
+			}
+			if event.Type == "cancel" || event.Type == "auth_revoked" {
+				return fmt.Errorf("stream %s", event.Type)
+			}
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+	return scanner.Err()
+}
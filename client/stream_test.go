@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newSSEServer returns a server which streams the given events to subscribers of /v0/item/1.json, then holds the
+// stream open until the client goes away.
+func newSSEServer(t *testing.T, events []string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/item/1.json" || r.Header.Get("Accept") != "text/event-stream" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprint(w, event)
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_SubscribeItem(t *testing.T) {
+	server := newSSEServer(t, []string{
+		"event: put\ndata: {\"path\":\"/\",\"data\":{\"id\":1,\"score\":5}}\n\n",
+		"event: keep-alive\ndata: null\n\n",
+		"event: patch\ndata: {\"path\":\"/\",\"data\":{\"score\":6}}\n\n",
+		"event: put\ndata: {\"path\":\"/descendants\",\"data\":3}\n\n",
+	})
+	c := New(WithBaseURL(server.URL+"/v0"), WithLogger(log.New(io.Discard, "", 0)))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := c.SubscribeItem(ctx, 1)
+	if err != nil {
+		t.Fatalf("SubscribeItem() error = %v", err)
+	}
+
+	var got []StreamEvent
+	for len(got) < 3 {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("SubscribeItem() stream closed after %d events", len(got))
+			}
+			got = append(got, event)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("SubscribeItem() timed out after %d events", len(got))
+		}
+	}
+	want := []StreamEvent{
+		{Type: "put", Path: "/", Data: json.RawMessage(`{"id":1,"score":5}`)},
+		{Type: "patch", Path: "/", Data: json.RawMessage(`{"score":6}`)},
+		{Type: "put", Path: "/descendants", Data: json.RawMessage(`3`)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SubscribeItem() events = %+v, want %+v", got, want)
+	}
+
+	// Cancelling the subscription closes the stream
+	cancel()
+	for range events {
+	}
+}
+
+func TestClient_SubscribeItemNotFound(t *testing.T) {
+	server := newSSEServer(t, nil)
+	c := New(WithBaseURL(server.URL+"/v0"), WithLogger(log.New(io.Discard, "", 0)))
+	if _, err := c.SubscribeItem(context.Background(), 2); err == nil {
+		t.Error("SubscribeItem() expected an error for a missing item")
+	}
+}
+
+func TestStreamEvent_ApplyTo(t *testing.T) {
+	item := Item{Id: 1, Title: "old", Score: 5, Comments: []Item{{Id: 2}}}
+	tests := []struct {
+		name  string
+		event StreamEvent
+		want  Item
+	}{
+		{
+			name:  "TestPutItem",
+			event: StreamEvent{Type: "put", Path: "/", Data: json.RawMessage(`{"id":1,"title":"new"}`)},
+			want:  Item{Id: 1, Title: "new", Comments: []Item{{Id: 2}}},
+		},
+		{
+			name:  "TestPatchItem",
+			event: StreamEvent{Type: "patch", Path: "/", Data: json.RawMessage(`{"score":9}`)},
+			want:  Item{Id: 1, Title: "old", Score: 9, Comments: []Item{{Id: 2}}},
+		},
+		{
+			name:  "TestPutField",
+			event: StreamEvent{Type: "put", Path: "/kids", Data: json.RawMessage(`[2,3]`)},
+			want:  Item{Id: 1, Title: "old", Score: 5, Kids: []int{2, 3}, Comments: []Item{{Id: 2}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.event.ApplyTo(item)
+			if err != nil {
+				t.Fatalf("ApplyTo() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyTo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
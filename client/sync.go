@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// SyncOptions describes what Sync stores for offline reading.
+type SyncOptions struct {
+	Feed  Feed
+	Depth int // How many levels of comments to store beneath each story, 0 for all of them
+	Limit int // How many stories from the top of the feed to store, 0 for all of them
+}
+
+// SyncProgress reports on a story Sync has finished with.
+type SyncProgress struct {
+	Done     int // How many stories have been handled so far, including this one
+	Total    int
+	Story    Item
+	Comments int   // How many comments were stored for the story
+	Skipped  bool  // Whether the story was stored by an earlier, interrupted sync
+	Err      error // Why the story couldn't be stored, if it couldn't
+}
+
+// syncCheckpoint records the progress of a sync, so an interrupted sync can pick up where it left off.
+type syncCheckpoint struct {
+	Feed     Feed  `json:"feed"`
+	Depth    int   `json:"depth"`
+	Limit    int   `json:"limit"`
+	StoryIds []int `json:"story_ids"`
+	Done     []int `json:"done"`
+}
+
+// Sync fetches a feed, its stories and their comment trees into the cache so they can be read offline. progress is
+// called after each story. If a previous sync with the same options was interrupted, the stories it finished are
+// skipped. Everything is fetched afresh, so stories stored by an earlier sync pick up the comments posted since.
+func (c *Client) Sync(ctx context.Context, opts SyncOptions, progress func(SyncProgress)) error {
+	if c.cache == nil {
+		return errors.New("sync needs the cache to store stories in")
+	}
+	if c.offline {
+		return errors.New("can't sync while offline")
+	}
+
+	ctx = withRefresh(ctx)
+
+	checkpointPath := filepath.Join(c.cache.dir, fmt.Sprintf("sync-%s.checkpoint", opts.Feed.Name()))
+	checkpoint, err := loadSyncCheckpoint(checkpointPath)
+	if err != nil || checkpoint.Depth != opts.Depth || checkpoint.Limit != opts.Limit {
+		// Start afresh, there's no interrupted sync we can resume
+		storyIds, err := c.GetFeed(ctx, opts.Feed)
+		if err != nil {
+			return err
+		}
+		checkpoint = syncCheckpoint{Feed: opts.Feed, Depth: opts.Depth, Limit: opts.Limit, StoryIds: limitIds(storyIds, opts.Limit)}
+	} else {
+		c.logger.Printf("Resuming sync of %s with %d of %d stories done", opts.Feed.Name(), len(checkpoint.Done), len(checkpoint.StoryIds))
+	}
+
+	var failed int
+	for i, storyId := range checkpoint.StoryIds {
+		p := SyncProgress{Done: i + 1, Total: len(checkpoint.StoryIds), Story: Item{Id: storyId}}
+		if slices.Contains(checkpoint.Done, storyId) {
+			p.Skipped = true
+			progress(p)
+			continue
+		}
+
+		p.Story, p.Err = c.GetItemTree(ctx, storyId, TreeOptions{MaxDepth: opts.Depth})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if missing := countUnavailable(p.Story); p.Err == nil && missing > 0 {
+			// GetItemTree keeps going when comments fail to load, but a story missing some isn't fit to read offline
+			p.Err = fmt.Errorf("%d comments couldn't be loaded", missing)
+		}
+		if p.Err != nil {
+			failed++
+		} else {
+			p.Comments = countComments(p.Story)
+			checkpoint.Done = append(checkpoint.Done, storyId)
+			if err := saveSyncCheckpoint(checkpointPath, checkpoint); err != nil {
+				c.logger.Printf("Error saving sync checkpoint: %v", err)
+			}
+		}
+		progress(p)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d stories couldn't be synced, run sync again to retry them", failed, len(checkpoint.StoryIds))
+	}
+	os.Remove(checkpointPath)
+	return nil
+}
+
+// limitIds returns at most limit of the IDs, or all of them if limit is 0.
+func limitIds(ids []int, limit int) []int {
+	if limit > 0 && len(ids) > limit {
+		return ids[:limit]
+	}
+	return ids
+}
+
+// countComments returns the number of comments in an item's comment tree, not counting placeholders for those which
+// couldn't be loaded.
+func countComments(item Item) int {
+	count := 0
+	for _, comment := range item.Comments {
+		if !comment.Unavailable {
+			count++
+		}
+		count += countComments(comment)
+	}
+	return count
+}
+
+// countUnavailable returns the number of placeholders for comments which couldn't be loaded in an item's comment tree.
+func countUnavailable(item Item) int {
+	count := 0
+	for _, comment := range item.Comments {
+		if comment.Unavailable {
+			count++
+		}
+		count += countUnavailable(comment)
+	}
+	return count
+}
+
+func loadSyncCheckpoint(path string) (syncCheckpoint, error) {
+	var checkpoint syncCheckpoint
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return checkpoint, err
+	}
+	err = json.Unmarshal(data, &checkpoint)
+	return checkpoint, err
+}
+
+func saveSyncCheckpoint(path string, checkpoint syncCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
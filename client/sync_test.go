@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_Sync(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/newstories.json": "[1, 2, 3]",
+		"/v0/item/1.json":     `{"id": 1, "type": "story", "kids": [4]}`,
+		"/v0/item/2.json":     `{"id": 2, "type": "story"}`,
+		"/v0/item/3.json":     `{"id": 3, "type": "story"}`,
+		"/v0/item/4.json":     `{"id": 4, "type": "comment", "text": "hi", "kids": [5]}`,
+		"/v0/item/5.json":     `{"id": 5, "type": "comment", "text": "hello"}`,
+	})
+	cache, err := OpenCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	c.cache = cache
+
+	// Pretend an earlier sync was interrupted after story 2
+	opts := SyncOptions{Feed: FeedNew, Depth: 1, Limit: 2}
+	checkpointPath := filepath.Join(cache.dir, "sync-new.checkpoint")
+	err = saveSyncCheckpoint(checkpointPath, syncCheckpoint{Feed: FeedNew, Depth: 1, Limit: 2, StoryIds: []int{2, 1}, Done: []int{2}})
+	if err != nil {
+		t.Fatalf("saveSyncCheckpoint() error = %v", err)
+	}
+
+	var got []SyncProgress
+	if err := c.Sync(context.Background(), opts, func(p SyncProgress) { got = append(got, p) }); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	want := []SyncProgress{
+		{Done: 1, Total: 2, Story: Item{Id: 2}, Skipped: true},
+		{Done: 2, Total: 2, Story: Item{Id: 1, Type: "story", Kids: []int{4}, Comments: []Item{
+			{Id: 4, Type: "comment", Text: "hi", Kids: []int{5}},
+		}}, Comments: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sync() progress = %+v, want %+v", got, want)
+	}
+	if _, err := loadSyncCheckpoint(checkpointPath); err == nil {
+		t.Error("Sync() should remove its checkpoint once it's done")
+	}
+
+	// What was synced is readable offline
+	offline := New(WithCache(cache), WithOffline(), WithLogger(log.New(io.Discard, "", 0)))
+	if _, err := offline.GetItem(context.Background(), 4); err != nil {
+		t.Errorf("GetItem() offline error = %v", err)
+	}
+}
+
+func TestClient_SyncMissingComments(t *testing.T) {
+	// Comment 3 fails to load
+	bodies := map[string]string{
+		"/v0/newstories.json": "[1]",
+		"/v0/item/1.json":     `{"id": 1, "type": "story", "kids": [2, 3]}`,
+		"/v0/item/2.json":     `{"id": 2, "type": "comment", "text": "hi"}`,
+	}
+	c := newTestClient(t, bodies)
+	cache, err := OpenCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	c.cache = cache
+
+	var got []SyncProgress
+	err = c.Sync(context.Background(), SyncOptions{Feed: FeedNew}, func(p SyncProgress) { got = append(got, p) })
+	if err == nil {
+		t.Fatal("Sync() error = nil, want the story counted as failed")
+	}
+	if len(got) != 1 || got[0].Err == nil {
+		t.Errorf("Sync() progress = %+v, want one failed story", got)
+	}
+
+	// The story is retried by the next sync, once the comment can be loaded
+	bodies["/v0/item/3.json"] = `{"id": 3, "type": "comment", "text": "hello"}`
+	got = nil
+	if err := c.Sync(context.Background(), SyncOptions{Feed: FeedNew}, func(p SyncProgress) { got = append(got, p) }); err != nil {
+		t.Fatalf("Sync() again error = %v", err)
+	}
+	if len(got) != 1 || got[0].Skipped || got[0].Comments != 2 {
+		t.Errorf("Sync() again progress = %+v, want the story synced with 2 comments", got)
+	}
+}
+
+func TestClient_SyncFetchesAfresh(t *testing.T) {
+	bodies := map[string]string{
+		"/v0/newstories.json": "[1]",
+		"/v0/item/1.json":     `{"id": 1, "type": "story", "kids": [4]}`,
+		"/v0/item/4.json":     `{"id": 4, "type": "comment", "text": "hi"}`,
+		"/v0/item/5.json":     `{"id": 5, "type": "comment", "text": "hello"}`,
+	}
+	c := newTestClient(t, bodies)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	cache, err := OpenCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	cache.now = clock.Now
+	c.cache = cache
+	if err := c.Sync(context.Background(), SyncOptions{Feed: FeedNew}, func(SyncProgress) {}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// A day later the story has a new comment, and what was cached has gone stale
+	bodies["/v0/item/1.json"] = `{"id": 1, "type": "story", "kids": [4, 5]}`
+	clock.now = clock.now.Add(24 * time.Hour)
+	var got []SyncProgress
+	if err := c.Sync(context.Background(), SyncOptions{Feed: FeedNew}, func(p SyncProgress) { got = append(got, p) }); err != nil {
+		t.Fatalf("Sync() again error = %v", err)
+	}
+	if len(got) != 1 || got[0].Comments != 2 {
+		t.Errorf("Sync() again progress = %+v, want the story synced with both comments", got)
+	}
+	c.revalidating.Range(func(endpoint, _ any) bool {
+		t.Errorf("Sync() left %v revalidating in the background", endpoint)
+		return true
+	})
+
+	offline := New(WithCache(cache), WithOffline(), WithLogger(log.New(io.Discard, "", 0)))
+	if item, err := offline.GetItem(context.Background(), 1); err != nil || !reflect.DeepEqual(item.Kids, []int{4, 5}) {
+		t.Errorf("GetItem() offline = %+v, %v, want the story with both comments", item, err)
+	}
+}
@@ -0,0 +1,85 @@
+package client
+
+import "context"
+
+// TreeOptions limits how much of a discussion GetItemTree loads. Zero values mean no limit.
+type TreeOptions struct {
+	MaxDepth    int // How many levels of replies to load, 1 being direct replies only
+	MaxComments int // How many comments to load in total, though direct replies are always all loaded
+}
+
+// GetItemTree fetches an item along with its replies, nested in Comments to an arbitrary depth. Replies are loaded
+// a level at a time, breadth first, so when a limit is hit the comments left out are the most deeply nested ones.
+// Comments which are left out keep their Kids but have no Comments, so callers can load them later. Direct replies are
+// never left out, as there'd be nothing to load them from, but they count towards MaxComments. Replies which
+// have no text or have been removed are skipped, and those which fail to load are kept as Unavailable placeholders.
+// A poll's options are loaded into Options.
+func (c *Client) GetItemTree(ctx context.Context, itemId int, opts TreeOptions) (Item, error) {
+	c.logger.Printf("Getting item tree %d", itemId)
+	item, err := c.GetItem(ctx, itemId)
+	if err != nil {
+		return Item{}, err
+	}
+	if item.IsPoll() {
+		if item.Options, err = c.GetPollOptions(ctx, item); err != nil {
+			c.logger.Printf("Error getting options of poll %d: %v", itemId, err)
+		}
+	}
+
+	loaded := 0
+	level := []*Item{&item}
+	for depth := 1; len(level) > 0 && (opts.MaxDepth == 0 || depth <= opts.MaxDepth); depth++ {
+		// Gather the IDs of every reply on this level, in thread order
+		var parents []*Item
+		var kidIds []int
+		for _, parent := range level {
+			for _, kidId := range parent.Kids {
+				if depth > 1 && opts.MaxComments > 0 && loaded+len(kidIds) >= opts.MaxComments {
+					break
+				}
+				parents = append(parents, parent)
+				kidIds = append(kidIds, kidId)
+			}
+		}
+		if len(kidIds) == 0 {
+			break
+		}
+		loaded += len(kidIds)
+
+		kids, err := c.GetItems(ctx, kidIds)
+		if err != nil {
+			c.logger.Printf("Error getting comments of %d at depth %d: %v", itemId, depth, err)
+		}
+		for i, kid := range kids {
+			if !isReadableComment(kid) {
+				continue
+			}
+			parents[i].Comments = append(parents[i].Comments, kid)
+		}
+
+		// The next level hangs off the comments we just attached. Their slices are complete now, so pointers into
+		// them stay valid.
+		var next []*Item
+		for _, parent := range level {
+			for i := range parent.Comments {
+				next = append(next, &parent.Comments[i])
+			}
+		}
+		level = next
+	}
+
+	if err := ctx.Err(); err != nil {
+		// Don't pass off a tree we gave up on part way through as complete
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// isReadableComment reports whether a comment has text worth showing, or is a placeholder for one we couldn't fetch.
+func isReadableComment(comment Item) bool {
+	if comment.Unavailable {
+		return true
+	}
+	// Skip comments which were removed, and any with no text
+	return !comment.Removed() && comment.Text != ""
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// commentIds returns the IDs of the comments in the tree, depth first, with a "/" marking each level of nesting.
+func commentIds(item Item, prefix string) []string {
+	var ids []string
+	for _, comment := range item.Comments {
+		id := prefix + string(rune('0'+comment.Id))
+		ids = append(ids, id)
+		ids = append(ids, commentIds(comment, id+"/")...)
+	}
+	return ids
+}
+
+func TestClient_GetItemTree(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/item/1.json": `{"id": 1, "type": "story", "kids": [2, 3]}`,
+		"/v0/item/2.json": `{"id": 2, "type": "comment", "text": "a", "kids": [4, 5]}`,
+		"/v0/item/3.json": `{"id": 3, "type": "comment", "text": "b", "kids": [6]}`,
+		"/v0/item/4.json": `{"id": 4, "type": "comment", "text": "c", "kids": [7]}`,
+		"/v0/item/5.json": `{"id": 5, "type": "comment", "text": "[flagged]", "dead": true}`,
+		"/v0/item/6.json": `{"id": 6, "type": "comment", "text": "d"}`,
+		"/v0/item/7.json": `{"id": 7, "type": "comment", "text": "e"}`,
+	})
+
+	tests := []struct {
+		name string
+		opts TreeOptions
+		want []string
+	}{
+		{
+			name: "TestUnlimited",
+			opts: TreeOptions{},
+			want: []string{"2", "2/4", "2/4/7", "3", "3/6"},
+		},
+		{
+			name: "TestMaxDepth",
+			opts: TreeOptions{MaxDepth: 2},
+			want: []string{"2", "2/4", "3", "3/6"},
+		},
+		{
+			name: "TestMaxComments",
+			opts: TreeOptions{MaxComments: 4},
+			want: []string{"2", "2/4", "3"},
+		},
+		{
+			name: "TestMaxCommentsKeepsDirectReplies",
+			opts: TreeOptions{MaxComments: 1},
+			want: []string{"2", "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.GetItemTree(context.Background(), 1, tt.opts)
+			if err != nil {
+				t.Fatalf("GetItemTree() error = %v", err)
+			}
+			if ids := commentIds(got, ""); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("GetItemTree() comments = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
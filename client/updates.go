@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Updates lists the items and profiles which have changed recently.
+type Updates struct {
+	Items    []int    `json:"items"`
+	Profiles []string `json:"profiles"`
+}
+
+// GetUpdates returns the items and profiles which have changed recently. Any cached responses for them are dropped, so
+// the next time they're asked for they're fetched fresh.
+func (c *Client) GetUpdates(ctx context.Context) (Updates, error) {
+	c.logger.Println("Getting updates")
+	var updates Updates
+	err := c.handleRequest(ctx, "GET", "updates.json", nil, &updates)
+	if err != nil {
+		return Updates{}, err
+	}
+
+	if c.cache != nil && !c.offline {
+		for _, itemId := range updates.Items {
+			c.cache.remove(fmt.Sprintf("item/%d.json", itemId))
+		}
+		for _, userId := range updates.Profiles {
+			c.cache.remove(fmt.Sprintf("user/%s.json", userId))
+		}
+	}
+	return updates, nil
+}
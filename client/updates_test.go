@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestClient_GetUpdates(t *testing.T) {
+	c := newTestClient(t, map[string]string{
+		"/v0/updates.json": `{"items": [1, 2], "profiles": ["pg"]}`,
+	})
+	cache, err := OpenCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	c.cache = cache
+	for _, key := range []string{"item/1.json", "item/3.json", "user/pg.json"} {
+		if err := cache.put(key, []byte(`{}`)); err != nil {
+			t.Fatalf("put() error = %v", err)
+		}
+	}
+
+	got, err := c.GetUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("GetUpdates() error = %v", err)
+	}
+	if want := (Updates{Items: []int{1, 2}, Profiles: []string{"pg"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetUpdates() = %v, want %v", got, want)
+	}
+
+	// The changed item and profile are dropped from the cache, the unchanged item is kept
+	for key, want := range map[string]bool{"item/1.json": false, "item/3.json": true, "user/pg.json": false} {
+		if body, _ := cache.get(key); (body != nil) != want {
+			t.Errorf("get(%s) cached = %v, want %v", key, body != nil, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// User is a Hacker News user profile.
+type User struct {
+	Id        string `json:"id"`
+	Created   int    `json:"created"`
+	Karma     int    `json:"karma"`
+	About     string `json:"about"`
+	Submitted []int  `json:"submitted"`
+}
+
+// CreatedAt returns the time the user's account was created.
+func (u User) CreatedAt() time.Time {
+	return time.Unix(int64(u.Created), 0)
+}
+
+// SubmissionsMenuResponse returns the user's stories, comments and polls, newest first, as items with only their IDs.
+func (u User) SubmissionsMenuResponse() TopMenuResponse {
+	topMenuResponse := TopMenuResponse{Items: make([]Item, 0, len(u.Submitted))}
+	for _, itemId := range u.Submitted {
+		topMenuResponse.Items = append(topMenuResponse.Items, Item{Id: itemId})
+	}
+	return topMenuResponse
+}
+
+// GetUser returns the profile of the user with the given case-sensitive ID.
+func (c *Client) GetUser(ctx context.Context, userId string) (User, error) {
+	c.logger.Printf("Getting user %s", userId)
+	var user User
+	err := c.handleRequest(ctx, "GET", fmt.Sprintf("user/%s.json", userId), nil, &user)
+	if err != nil {
+		return User{}, err
+	}
+	if user.Id == "" {
+		// The API responds with null for users that don't exist
+		return User{}, fmt.Errorf("user %s not found", userId)
+	}
+	return user, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// openConfigFile loads one of the files we keep under the user's config directory, e.g. ~/.config/hn/history.json.
+func openConfigFile[T any](name string, load func(path string) (T, error)) (T, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return load(filepath.Join(dir, "hn", name))
+}
+
+// loadJSONFile decodes the JSON file at path into v. Nothing is loaded if the path is empty or there's no file there
+// yet.
+func loadJSONFile(path string, v any) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// saveJSONFile writes v to path as JSON, unless the path is empty. The file is written under a temporary name then
+// renamed into place, so an interrupted save can't leave it half written and other instances of the app saving at
+// the same time don't trip over each other.
+func saveJSONFile(path string, v any) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_saveJSONFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hn")
+	path := filepath.Join(dir, "test.json")
+
+	var missing map[string]int
+	if err := loadJSONFile(path, &missing); err != nil || missing != nil {
+		t.Fatalf("loadJSONFile() of a missing file = %v, %v, want nothing loaded", missing, err)
+	}
+
+	want := map[string]int{"a": 1}
+	if err := saveJSONFile(path, want); err != nil {
+		t.Fatalf("saveJSONFile() error = %v", err)
+	}
+	var got map[string]int
+	if err := loadJSONFile(path, &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("loadJSONFile() = %v, %v, want %v", got, err, want)
+	}
+
+	// Nothing is left behind but the file itself
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "test.json" {
+		t.Errorf("saveJSONFile() left %v in its directory", entries)
+	}
+}
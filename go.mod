@@ -10,7 +10,6 @@ require (
 )
 
 require (
-	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
 	github.com/containerd/console v1.0.4-0.20230313162750-1ae8d489ac81 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
@@ -18,8 +17,6 @@ require (
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/muesli/ansi v0.0.0-20230316100256-276c6243b2f6 // indirect
 	github.com/muesli/cancelreader v0.2.2 // indirect
-	github.com/muesli/reflow v0.3.0 // indirect
-	github.com/muesli/termenv v0.15.2 // indirect
 	github.com/rivo/uniseg v0.4.4 // indirect
 	golang.org/x/sync v0.4.0 // indirect
 	golang.org/x/sys v0.18.0 // indirect
@@ -27,4 +24,9 @@ require (
 	golang.org/x/text v0.14.0 // indirect
 )
 
-require golang.org/x/net v0.22.0
+require (
+	github.com/aymanbagabas/go-osc52/v2 v2.0.1
+	github.com/muesli/reflow v0.3.0
+	github.com/muesli/termenv v0.15.2
+	golang.org/x/net v0.22.0
+)
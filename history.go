@@ -0,0 +1,84 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/dominickp/hn/client"
+)
+
+// maxHistory is how many stories the read history remembers. The least recently visited are forgotten first.
+const maxHistory = 10000
+
+// visit records when we last opened a story and how many comments it had then.
+type visit struct {
+	VisitedAt time.Time `json:"visited_at"`
+	Comments  int       `json:"comments"`
+}
+
+// readHistory is the stories the user has opened, saved between sessions so they can see what's new.
+type readHistory struct {
+	path   string        // Where the history is saved, or "" to keep it in memory
+	visits map[int]visit // Keyed by story ID
+}
+
+// loadHistory loads the read history saved at path, or starts a new one if nothing has been saved there yet. An empty
+// path keeps the history in memory.
+func loadHistory(path string) (*readHistory, error) {
+	h := &readHistory{path: path, visits: map[int]visit{}}
+	return h, loadJSONFile(path, &h.visits)
+}
+
+// markRead records that we've opened a story and seen the comments it has now.
+func (h *readHistory) markRead(story client.Item, now time.Time) {
+	if h == nil || story.Unavailable || story.IsComment() {
+		return
+	}
+	h.visits[story.Id] = visit{VisitedAt: now, Comments: story.CommentCount()}
+	if len(h.visits) > maxHistory {
+		h.forgetOldest(len(h.visits) - maxHistory)
+	}
+}
+
+// forgetOldest forgets the n least recently visited stories.
+func (h *readHistory) forgetOldest(n int) {
+	ids := make([]int, 0, len(h.visits))
+	for id := range h.visits {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b int) int {
+		return h.visits[a].VisitedAt.Compare(h.visits[b].VisitedAt)
+	})
+	for _, id := range ids[:n] {
+		delete(h.visits, id)
+	}
+}
+
+// isRead reports whether we've opened the story before.
+func (h *readHistory) isRead(storyId int) bool {
+	if h == nil {
+		return false
+	}
+	_, ok := h.visits[storyId]
+	return ok
+}
+
+// newComments returns how many comments a story has gained since we last opened it, or 0 if we never have.
+func (h *readHistory) newComments(story client.Item) int {
+	if h == nil {
+		return 0
+	}
+	v, ok := h.visits[story.Id]
+	if !ok {
+		return 0
+	}
+	return max(0, story.CommentCount()-v.Comments)
+}
+
+// save writes the history to its path, if it has one.
+func (h *readHistory) save() error {
+	if h == nil {
+		return nil
+	}
+	return saveJSONFile(h.path, h.visits)
+}
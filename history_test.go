@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominickp/hn/client"
+)
+
+func Test_readHistory_saveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hn", "history.json")
+	h, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory() of a new history error = %v", err)
+	}
+	visitedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	h.markRead(client.Item{Id: 1, Type: "story", Descendants: 12}, visitedAt)
+	h.markRead(client.Item{Id: 2, Type: "comment"}, visitedAt)
+	if err := h.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	loaded, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory() error = %v", err)
+	}
+	if got := loaded.visits[1]; !got.VisitedAt.Equal(visitedAt) || got.Comments != 12 {
+		t.Errorf("loadHistory() visit = %+v, want the visit we saved", got)
+	}
+	if loaded.isRead(2) {
+		t.Errorf("loadHistory() should only remember stories, not comments")
+	}
+}
+
+func Test_readHistory_loadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadHistory(path); err == nil {
+		t.Errorf("loadHistory() of a corrupt history should fail rather than start over")
+	}
+}
+
+func Test_readHistory_newComments(t *testing.T) {
+	h := &readHistory{visits: map[int]visit{1: {Comments: 10}}}
+	tests := []struct {
+		name  string
+		story client.Item
+		want  int
+	}{
+		{name: "TestNewComments", story: client.Item{Id: 1, Type: "story", Descendants: 15}, want: 5},
+		{name: "TestNoNewComments", story: client.Item{Id: 1, Type: "story", Descendants: 10}, want: 0},
+		{name: "TestCommentsRemoved", story: client.Item{Id: 1, Type: "story", Descendants: 8}, want: 0},
+		{name: "TestNeverRead", story: client.Item{Id: 2, Type: "story", Descendants: 8}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.newComments(tt.story); got != tt.want {
+				t.Errorf("newComments() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_readHistory_forgetOldest(t *testing.T) {
+	now := time.Now()
+	h := &readHistory{visits: map[int]visit{
+		1: {VisitedAt: now.Add(-time.Hour)},
+		2: {VisitedAt: now.Add(-3 * time.Hour)},
+		3: {VisitedAt: now.Add(-2 * time.Hour)},
+	}}
+	h.forgetOldest(2)
+	if !h.isRead(1) || h.isRead(2) || h.isRead(3) {
+		t.Errorf("forgetOldest() left %v, want only the most recent visit", h.visits)
+	}
+}
+
+func Test_getTopMenuCurrentPageChoicesNewComments(t *testing.T) {
+	m := model{
+		currentPage: 1,
+		pageSize:    2,
+		history:     &readHistory{visits: map[int]visit{1: {Comments: 3}}},
+		topMenuResponse: client.TopMenuResponse{Items: []client.Item{
+			{Id: 1, Type: "story", Title: "read", Descendants: 7},
+			{Id: 2, Type: "story", Title: "unread", Descendants: 7},
+		}},
+	}
+	choices := getTopMenuCurrentPageChoices(m)
+	if !strings.HasSuffix(choices[0], "read +4 new comments") || strings.Contains(choices[1], "new comments") {
+		t.Errorf("getTopMenuCurrentPageChoices() = %q, want new comments shown on the read story", choices)
+	}
+}
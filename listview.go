@@ -0,0 +1,160 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
+)
+
+// sortOrder is the order the stories in a list are shown in.
+type sortOrder int
+
+const (
+	sortFeed     sortOrder = iota // The order the feed gave us
+	sortScore                     // Highest score first
+	sortAge                       // Newest first
+	sortComments                  // Most comments first
+	sortDomain                    // Alphabetically by the site they link to
+)
+
+func (s sortOrder) String() string {
+	switch s {
+	case sortScore:
+		return "score"
+	case sortAge:
+		return "age"
+	case sortComments:
+		return "comments"
+	case sortDomain:
+		return "domain"
+	}
+	return "feed"
+}
+
+// minScoreSteps are the minimum scores the user can cycle through.
+var minScoreSteps = []int{0, 10, 50, 100, 250, 500}
+
+// listOptions sorts and filters the stories in a list.
+type listOptions struct {
+	sort     sortOrder
+	minScore int    // Hide stories with a lower score
+	keyword  string // Hide stories whose title, author and domain don't contain this, ignoring case
+	hideRead bool   // Hide stories we've already opened
+}
+
+// active reports whether the list is sorted or filtered, in which case we need every item in it loaded.
+func (o listOptions) active() bool {
+	return o != listOptions{}
+}
+
+// nextSort returns the options with the next sort order.
+func (o listOptions) nextSort() listOptions {
+	o.sort = (o.sort + 1) % (sortDomain + 1)
+	return o
+}
+
+// nextMinScore returns the options with the next minimum score, wrapping around to no minimum.
+func (o listOptions) nextMinScore() listOptions {
+	i := slices.Index(minScoreSteps, o.minScore)
+	o.minScore = minScoreSteps[(i+1)%len(minScoreSteps)]
+	return o
+}
+
+// apply returns the items the options let through, in their order. Items we haven't loaded yet can't be sorted or
+// filtered, so they're left out until they're loaded.
+func (o listOptions) apply(items []client.Item, history *readHistory) []client.Item {
+	if !o.active() {
+		return items
+	}
+	keyword := strings.ToLower(o.keyword)
+	var listed []client.Item
+	for _, item := range items {
+		switch {
+		case item.Type == "" && !item.Unavailable:
+		case item.Score < o.minScore:
+		case o.hideRead && history.isRead(item.Id):
+		case keyword != "" && !strings.Contains(strings.ToLower(item.Title+" "+item.By+" "+item.Domain()), keyword):
+		default:
+			listed = append(listed, item)
+		}
+	}
+	slices.SortStableFunc(listed, func(a, b client.Item) int {
+		switch o.sort {
+		case sortScore:
+			return cmp.Compare(b.Score, a.Score)
+		case sortAge:
+			return cmp.Compare(b.Time, a.Time)
+		case sortComments:
+			return cmp.Compare(b.CommentCount(), a.CommentCount())
+		case sortDomain:
+			// Self posts have no domain, so they go last
+			if (a.Domain() == "") != (b.Domain() == "") {
+				return cmp.Compare(b.Domain(), a.Domain())
+			}
+			return cmp.Compare(a.Domain(), b.Domain())
+		}
+		return 0
+	})
+	return listed
+}
+
+// String describes the options for the header, or returns "" if they're not active.
+func (o listOptions) String() string {
+	var parts []string
+	if o.sort != sortFeed {
+		parts = append(parts, "sort: "+o.sort.String())
+	}
+	if o.minScore > 0 {
+		parts = append(parts, fmt.Sprintf("≥%d points", o.minScore))
+	}
+	if o.keyword != "" {
+		parts = append(parts, fmt.Sprintf("%q", o.keyword))
+	}
+	if o.hideRead {
+		parts = append(parts, "unread")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// listedItems returns the items in the list we're showing, sorted and filtered.
+func (m model) listedItems() []client.Item {
+	return m.listOptions.apply(m.topMenuResponse.Items, m.history)
+}
+
+// setListOptions sorts and filters the list with new options, going back to the top of it. If the options need every
+// item loaded, the rest are loaded in the background.
+func (m model) setListOptions(options listOptions) (model, tea.Cmd) {
+	m.listOptions = options
+	m.currentPage = 1
+	m.cursor = 0
+	m.choices = getTopMenuCurrentPageChoices(m)
+	m.viewport.SetContent(getContent(m))
+	m.viewport.GotoTop()
+	return m, m.RedrawPage()
+}
+
+// updateKeywordFilter handles keys while the user is typing the keyword to filter the list by, filtering as they
+// type.
+func (m model) updateKeywordFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	options := m.listOptions
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		options.keyword = ""
+	default:
+		options.keyword, _ = editLine(options.keyword, msg)
+	}
+	if options == m.listOptions {
+		m.viewport.SetContent(getContent(m))
+		return m, nil
+	}
+	return m.setListOptions(options)
+}
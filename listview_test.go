@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dominickp/hn/client"
+)
+
+func Test_listOptions_apply(t *testing.T) {
+	items := []client.Item{
+		{Id: 1, Type: "story", Title: "Go 1.22", By: "rsc", Url: "https://go.dev/blog", Score: 300, Time: 100, Descendants: 5},
+		{Id: 2, Type: "story", Title: "Ask HN: Rust or Go?", By: "pg", Score: 40, Time: 300, Descendants: 90},
+		{Id: 3, Type: "story", Title: "Zig", By: "andy", Url: "https://www.ziglang.org", Score: 120, Time: 200, Descendants: 30},
+		{Id: 4},
+		{Id: 5, Type: "story", Title: "Assembly", By: "ken", Url: "https://asm.example.com", Score: 10, Time: 50},
+	}
+	history := &readHistory{visits: map[int]visit{3: {}}}
+
+	tests := []struct {
+		name    string
+		options listOptions
+		want    []int
+	}{
+		{name: "TestFeedOrder", options: listOptions{}, want: []int{1, 2, 3, 4, 5}},
+		{name: "TestSortScore", options: listOptions{sort: sortScore}, want: []int{1, 3, 2, 5}},
+		{name: "TestSortAge", options: listOptions{sort: sortAge}, want: []int{2, 3, 1, 5}},
+		{name: "TestSortComments", options: listOptions{sort: sortComments}, want: []int{2, 3, 1, 5}},
+		{name: "TestSortDomain", options: listOptions{sort: sortDomain}, want: []int{5, 1, 3, 2}},
+		{name: "TestMinScore", options: listOptions{minScore: 100}, want: []int{1, 3}},
+		{name: "TestKeyword", options: listOptions{keyword: "GO"}, want: []int{1, 2}},
+		{name: "TestKeywordDomain", options: listOptions{keyword: "ziglang"}, want: []int{3}},
+		{name: "TestHideRead", options: listOptions{hideRead: true, sort: sortScore}, want: []int{1, 2, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, item := range tt.options.apply(items, history) {
+				got = append(got, item.Id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_listOptions_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		options listOptions
+		want    string
+	}{
+		{name: "TestInactive", options: listOptions{}, want: ""},
+		{name: "TestSort", options: listOptions{sort: sortComments}, want: "sort: comments"},
+		{
+			name:    "TestEverything",
+			options: listOptions{sort: sortAge, minScore: 50, keyword: "go", hideRead: true},
+			want:    `sort: age · ≥50 points · "go" · unread`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_listOptions_cycle(t *testing.T) {
+	options := listOptions{}
+	for _, want := range []sortOrder{sortScore, sortAge, sortComments, sortDomain, sortFeed} {
+		if options = options.nextSort(); options.sort != want {
+			t.Errorf("nextSort() = %v, want %v", options.sort, want)
+		}
+	}
+	for _, want := range []int{10, 50, 100, 250, 500, 0} {
+		if options = options.nextMinScore(); options.minScore != want {
+			t.Errorf("nextMinScore() = %d, want %d", options.minScore, want)
+		}
+	}
+}
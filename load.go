@@ -0,0 +1,35 @@
+package main
+
+import "context"
+
+// loader is a load started by the user navigating somewhere. Its context is cancelled when they navigate elsewhere.
+type loader struct {
+	ctx context.Context
+	id  int
+}
+
+// loadTracker keeps track of the load for the screen the user is on, so it can be cancelled when they navigate away
+// and any late messages from it can be ignored.
+type loadTracker struct {
+	id     int
+	cancel context.CancelFunc
+}
+
+// start cancels the current load and starts a new one.
+func (t *loadTracker) start() loader {
+	if t == nil {
+		return loader{ctx: context.Background()}
+	}
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.id++
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	return loader{ctx: ctx, id: t.id}
+}
+
+// isCurrent reports whether messages from the load with the given ID are still wanted.
+func (t *loadTracker) isCurrent(id int) bool {
+	return t == nil || id == t.id
+}
@@ -1,21 +1,90 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
 	"github.com/dominickp/hn/logger"
+	"github.com/dominickp/hn/util"
 )
 
 const logfilePath = "logs/bubbletea.log"
 
+// newClient returns the API client for the app, backed by the on-disk cache unless noCache is set. While offline,
+// requests are only served from the cache.
+func newClient(noCache, offline bool) *client.Client {
+	var opts []client.Option
+	if offline {
+		opts = append(opts, client.WithOffline())
+	}
+	if !noCache {
+		cache, err := openCache()
+		if err != nil {
+			logger.Logger.Printf("Running without a cache: %v", err)
+		} else {
+			opts = append(opts, client.WithCache(cache))
+		}
+	}
+	return client.New(opts...)
+}
+
+// openCache opens the response cache in its default location.
+func openCache() (*client.Cache, error) {
+	dir, err := client.DefaultCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return client.OpenCache(dir)
+}
+
 func main() {
 	logger.Init(logfilePath)
 
+	if len(os.Args) > 1 && os.Args[1] == "sync" {
+		if err := runSync(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "sync: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "saved" {
+		if err := runSaved(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "saved: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	noCache := flag.Bool("no-cache", false, "don't read or write the on-disk response cache")
+	offline := flag.Bool("offline", false, "browse only what's in the on-disk response cache")
+	stream := flag.Bool("stream", false, "stream live changes to the topic on screen instead of waiting for the next poll")
+	noHighlight := flag.Bool("no-highlight", false, "don't syntax highlight code blocks, e.g. on a monochrome terminal")
+	flag.Parse()
+	util.Hyperlinks = util.SupportsHyperlinks(os.Getenv)
+	util.Highlight = !*noHighlight
+
+	c := newClient(*noCache, *offline)
+	defer c.Close()
+	m := initialModel(c)
+	if *stream && !*offline {
+		m.stream = &itemStream{}
+	}
+	if history, err := openConfigFile("history.json", loadHistory); err != nil {
+		logger.Logger.Printf("Not saving read history: %v", err)
+	} else {
+		m.history = history
+	}
+	if bookmarks, err := openConfigFile("bookmarks.json", loadBookmarks); err != nil {
+		logger.Logger.Printf("Not saving bookmarks: %v", err)
+	} else {
+		m.bookmarks = bookmarks
+	}
 	p := tea.NewProgram(
-		initialModel(),
+		m,
 		tea.WithAltScreen(),       // use the full size of the terminal in its "alternate screen buffer"
 		tea.WithMouseCellMotion(), // turn on mouse support so we can track the mouse wheel
 	)
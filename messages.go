@@ -1,50 +1,132 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/dominickp/hn/client"
+	log "github.com/dominickp/hn/logger"
+)
+
+// Limits on how much of a discussion we load when opening a topic. Every direct reply is loaded whatever the limit,
+// and deeper replies we left out can be loaded by focusing on their thread.
+const (
+	maxTopicDepth    = 8
+	maxTopicComments = 200
 )
 
-func checkTopMenu(pageSize, page int) tea.Msg {
-	topMenuResponse, err := client.GetTopMenuResponse()
-	topMenuResponse.EnrichItems(pageSize, page)
+func checkTopMenu(l loader, c *client.Client, feed client.Feed, pageSize, page int) tea.Msg {
+	topMenuResponse, err := c.GetFeedMenuResponse(l.ctx, feed)
+	if errors.Is(err, client.ErrUnavailable) {
+		// The feed was never cached, so show it as empty while offline
+		return topMenuMsg{load: l.id}
+	}
 	if err != nil {
 		// There was an error making our request. Wrap the error we received
 		// in a message and return it.
-		return errMsg{err}
+		return errMsg{op: "loading the " + feed.Name() + " feed", err: err, load: l.id, retry: func(l loader) tea.Msg {
+			return checkTopMenu(l, c, feed, pageSize, page)
+		}}
 	}
-	return topMenuMsg(topMenuResponse)
+	if err := topMenuResponse.EnrichItems(l.ctx, c, pageSize, page); err != nil {
+		log.Logger.Printf("Error enriching page %d: %v", page, err)
+	}
+	return topMenuMsg{response: topMenuResponse, load: l.id}
 }
 
-func checkTopMenuPage(topMenuResponse client.TopMenuResponse, pageSize, page int) tea.Msg {
-	topMenuResponse.EnrichItems(pageSize, page)
-	return checkTopMenuPageMsg(topMenuResponse)
+// checkTopMenuPage loads the details of the items on a page of the top menu. It needs its own copy of the menu, as
+// the one on screen is only changed by Update once the details are back.
+func checkTopMenuPage(l loader, c *client.Client, topMenuResponse client.TopMenuResponse, pageSize, page int) tea.Msg {
+	if err := topMenuResponse.EnrichItems(l.ctx, c, pageSize, page); err != nil {
+		log.Logger.Printf("Error enriching page %d: %v", page, err)
+	}
+	return checkTopMenuPageMsg{response: topMenuResponse, load: l.id}
 }
 
-func checkTopic(topicID int) tea.Msg {
-	item, err := client.GetItemWithComments(topicID, 10)
+func checkTopic(l loader, c *client.Client, topicID int) tea.Msg {
+	item, err := c.GetItemTree(l.ctx, topicID, client.TreeOptions{MaxDepth: maxTopicDepth, MaxComments: maxTopicComments})
+	if errors.Is(err, client.ErrUnavailable) {
+		return topicMsg{item: client.Item{Id: topicID, Unavailable: true}, load: l.id}
+	}
 
 	if err != nil {
 		// There was an error making our request. Wrap the error we received
 		// in a message and return it.
-		return errMsg{err}
+		return errMsg{op: "loading topic", id: topicID, err: err, load: l.id, retry: func(l loader) tea.Msg {
+			return checkTopic(l, c, topicID)
+		}}
 	}
 
 	// We received a response from the server. Return the HTTP status code
 	// as a message.
-	return topicMsg(item)
+	return topicMsg{item: item, load: l.id}
+}
+
+func checkUser(l loader, c *client.Client, userId string) tea.Msg {
+	user, err := c.GetUser(l.ctx, userId)
+	if err != nil {
+		return errMsg{op: "loading user " + userId, err: err, load: l.id, retry: func(l loader) tea.Msg {
+			return checkUser(l, c, userId)
+		}}
+	}
+	return userMsg{user: user, load: l.id}
+}
+
+func checkGlobalSearch(l loader, c *client.Client, query string) tea.Msg {
+	page, err := c.GlobalSearch(l.ctx, client.SearchQuery{Query: query, HitsPerPage: maxSearchResults})
+	if err != nil {
+		return errMsg{op: fmt.Sprintf("searching Hacker News for %q", query), err: err, load: l.id, retry: func(l loader) tea.Msg {
+			return checkGlobalSearch(l, c, query)
+		}}
+	}
+	return globalSearchMsg{query: query, response: page.MenuResponse(), load: l.id}
 }
 
 func checkNothing() tea.Msg {
 	return nil
 }
 
-type topMenuMsg client.TopMenuResponse
-type topicMsg client.Item
-type errMsg struct{ err error }
-type checkTopMenuPageMsg client.TopMenuResponse
+// Each message records the ID of the load it came from, so we can ignore it if the user has since navigated away.
+type topMenuMsg struct {
+	response client.TopMenuResponse
+	load     int
+}
+type topicMsg struct {
+	item client.Item
+	load int
+}
+type userMsg struct {
+	user client.User
+	load int
+}
+type checkTopMenuPageMsg struct {
+	response client.TopMenuResponse
+	load     int
+}
+type globalSearchMsg struct {
+	query    string
+	response client.TopMenuResponse
+	load     int
+}
+
+// errMsg is sent when a command fails. It records what failed, so we can tell the user and let them retry it.
+type errMsg struct {
+	op    string               // What we were doing, e.g. "loading topic"
+	id    int                  // The ID of the item we were loading, if any
+	err   error                // Why it failed
+	load  int                  // The load the command was part of
+	retry func(loader) tea.Msg // Runs the failed command again as part of a new load
+}
 
 // Error implements error.
 func (e errMsg) Error() string {
-	panic("unimplemented")
+	if e.id != 0 {
+		return fmt.Sprintf("%s %d: %v", e.op, e.id, e.err)
+	}
+	return fmt.Sprintf("%s: %v", e.op, e.err)
+}
+
+func (e errMsg) Unwrap() error {
+	return e.err
 }
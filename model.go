@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/dominickp/hn/logger"
 
@@ -12,10 +14,12 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dominickp/hn/client"
 	"github.com/dominickp/hn/util"
+	"github.com/muesli/reflow/truncate"
 )
 
 type model struct {
-	choices           []string // items on the to-do list
+	client            *client.Client // Hacker News API client used by our commands
+	choices           []string       // items on the to-do list
 	topMenuResponse   client.TopMenuResponse
 	cursor            int           // which to-do list item our cursor is pointing at
 	err               error         // an error to display, if any
@@ -25,16 +29,144 @@ type model struct {
 	viewport          viewport.Model
 	pageSize          int
 	currentPage       int
+	feed              client.Feed               // The feed shown in the top menu
+	feedStates        map[client.Feed]feedState // Saved state of the feeds we're not looking at
+	profile           *profileView              // The user profile shown in place of the top menu, if any
+	comments          []commentRow              // The comments behind the choices when viewing a topic
+	folded            map[int]bool              // IDs of the comments whose threads are folded
+	loads             *loadTracker              // The load for the screen we're on
+	newReplies        map[int]bool              // IDs of the items which have had new replies since we loaded them
+	search            *searchView               // The search listed in place of the top menu, if any
+	stream            *itemStream               // Live changes to the topic on screen, nil unless streaming is on
+	listOptions       listOptions               // How the list we're showing is sorted and filtered
+	filtering         bool                      // Whether keys go to the keyword the list is filtered by
+	history           *readHistory              // The stories we've opened, this session and before
+	bookmarks         *bookmarkStore            // The stories and comments we've saved
+	saving            *saveDraft                // The bookmark we're filling in, if any
+	browser           *browser                  // Opens links, nil to ignore them
+	notice            string                    // A message for the user, shown until they press a key
 }
 
-func initialModel() model {
+// feedState is the top menu state of a feed, saved while the user is looking at another feed.
+type feedState struct {
+	topMenuResponse client.TopMenuResponse
+	currentPage     int
+	cursor          int
+}
+
+func initialModel(c *client.Client) model {
 	return model{
+		client:      c,
 		choices:     []string{},
 		pageSize:    15,
 		currentPage: 1,
+		feed:        client.FeedTop,
+		feedStates:  map[client.Feed]feedState{},
+		folded:      map[int]bool{},
+		loads:       &loadTracker{},
+		newReplies:  map[int]bool{},
+		history:     &readHistory{visits: map[int]visit{}},
+		bookmarks:   &bookmarkStore{},
+		browser:     newBrowser(),
+	}
+}
+
+// navState is a snapshot of where the user is in the navigation, so we can take them back there.
+type navState struct {
+	topMenuResponse   client.TopMenuResponse
+	currentPage       int
+	cursor            int
+	topicHistoryStack []client.Item
+	profile           *profileView
+	search            *searchView
+}
+
+// navState returns a snapshot of where the user is in the navigation.
+func (m model) navState() navState {
+	return navState{
+		topMenuResponse:   m.topMenuResponse,
+		currentPage:       m.currentPage,
+		cursor:            m.cursor,
+		topicHistoryStack: m.topicHistoryStack,
+		profile:           m.profile,
+		search:            m.search,
 	}
 }
 
+// restoreNavState takes the user back to where they were when the snapshot was taken.
+func (m model) restoreNavState(state navState) model {
+	m.topMenuResponse = state.topMenuResponse
+	m.currentPage = state.currentPage
+	m.cursor = state.cursor
+	m.topicHistoryStack = state.topicHistoryStack
+	m.profile = state.profile
+	m.search = state.search
+	return m
+}
+
+// profileView is a user's profile. While it's open, the user's submissions are listed in place of the top menu.
+type profileView struct {
+	user     client.User
+	previous navState // Where the profile was opened from
+}
+
+// openProfile shows the given user's profile and submissions, remembering where we opened it from.
+func (m model) openProfile(user client.User) model {
+	m.profile = &profileView{user: user, previous: m.navState()}
+	m.topMenuResponse = user.SubmissionsMenuResponse()
+	m.currentPage = 1
+	m.cursor = 0
+	m.topicHistoryStack = nil
+	m.search = nil
+	return m
+}
+
+// closeProfile takes us back to wherever the current profile was opened from.
+func (m model) closeProfile() model {
+	return m.restoreNavState(m.profile.previous)
+}
+
+// getCursorAuthor returns the author of the story or comment the cursor is pointing at.
+func (m model) getCursorAuthor() string {
+	topic := m.getCurrentTopic()
+	if topic != nil {
+		if m.cursor < len(m.comments) {
+			return m.comments[m.cursor].comment.By
+		}
+		return ""
+	}
+	itemIndex := (m.currentPage-1)*m.pageSize + m.cursor
+	if items := m.listedItems(); itemIndex < len(items) {
+		return items[itemIndex].By
+	}
+	return ""
+}
+
+// switchFeed saves the state of the current feed and restores the state of the given one, starting it on page 1 if
+// we haven't seen it before.
+func (m model) switchFeed(feed client.Feed) model {
+	m.feedStates[m.feed] = feedState{topMenuResponse: m.topMenuResponse, currentPage: m.currentPage, cursor: m.cursor}
+	state, ok := m.feedStates[feed]
+	if !ok {
+		state = feedState{currentPage: 1}
+	}
+	m.feed = feed
+	m.topMenuResponse = state.topMenuResponse
+	m.currentPage = state.currentPage
+	m.cursor = state.cursor
+	return m
+}
+
+// nextFeed returns the feed which is offset places away from the current one, wrapping around at either end.
+func (m model) nextFeed(offset int) client.Feed {
+	for i, feed := range tabs {
+		if feed == m.feed {
+			return tabs[(i+offset+len(tabs))%len(tabs)]
+		}
+	}
+	return client.FeedTop
+}
+
 // getCurrentTopic returns the current topic we're viewing from the top of the stack
 func (m model) getCurrentTopic() *client.Item {
 	if len(m.topicHistoryStack) > 0 {
@@ -43,49 +175,117 @@ func (m model) getCurrentTopic() *client.Item {
 	return nil
 }
 
+// Init, RedrawPage, InitUser and InitTopic each start a new load, cancelling whatever was loading for the screen we
+// were on.
 func (m model) Init() tea.Cmd {
+	l := m.loads.start()
 	return func() tea.Msg {
+		if m.profile != nil {
+			return checkUser(l, m.client, m.profile.user.Id)
+		}
 		// Don't draw the top menu until we have the viewport size ready
+		if m.ready && m.feed == feedSaved {
+			return topMenuMsg{response: m.bookmarks.menuResponse(), load: l.id}
+		}
 		if m.ready {
-			return checkTopMenu(m.pageSize, m.currentPage) // Get the top 500 stories and save to our cache
+			return checkTopMenu(l, m.client, m.feed, m.pageSize, m.currentPage) // Get the top 500 stories and save to our cache
 		}
 		return checkNothing()
 	}
 }
 
 func (m model) RedrawPage() tea.Cmd {
+	l := m.loads.start()
+	topMenuResponse := client.TopMenuResponse{Items: slices.Clone(m.topMenuResponse.Items)}
 	return func() tea.Msg {
 		if m.getCurrentTopic() != nil {
-			return checkTopic(m.getCurrentTopic().Id)
+			return checkTopic(l, m.client, m.getCurrentTopic().Id)
+		}
+		if len(m.topMenuResponse.Items) > 0 && m.listOptions.active() {
+			// Sorting and filtering the list needs every item in it
+			return checkTopMenuPage(l, m.client, topMenuResponse, len(topMenuResponse.Items), 1)
 		}
 		if len(m.topMenuResponse.Items) > 0 {
-			return checkTopMenuPage(m.topMenuResponse, m.pageSize, m.currentPage)
+			return checkTopMenuPage(l, m.client, topMenuResponse, m.pageSize, m.currentPage)
 		}
 		return checkNothing()
 	}
 }
 
+// applyTopMenuPage fills in the items in the top menu which have only their IDs with the details loaded for them by
+// checkTopMenuPage. Items which have changed since the page was loaded, e.g. by an update, are kept as they are.
+func (m model) applyTopMenuPage(loaded client.TopMenuResponse) model {
+	items := m.topMenuResponse.Items
+	for i := range min(len(items), len(loaded.Items)) {
+		if items[i].Type == "" && loaded.Items[i].Id == items[i].Id {
+			items[i] = loaded.Items[i]
+		}
+	}
+	return m
+}
+
+func (m model) InitUser(userId string) tea.Cmd {
+	l := m.loads.start()
+	return func() tea.Msg {
+		return checkUser(l, m.client, userId)
+	}
+}
+
 func (m model) InitTopic() tea.Cmd {
+	l := m.loads.start()
 	return func() tea.Msg {
 		if m.nextTopicId != 0 {
-			return checkTopic(m.nextTopicId)
+			return checkTopic(l, m.client, m.nextTopicId)
 		}
 		return checkNothing()
 	}
 }
 
-// getTopMenuCurrentPageChoices returns the current page of saved top menu items as choices
+// getTopMenuCurrentPageChoices returns the current page of saved top menu items as choices, sorted and filtered
 func getTopMenuCurrentPageChoices(m model) []string {
 	choices := make([]string, m.pageSize)
-	start := (m.currentPage - 1) * m.pageSize
-	end := min(start+m.pageSize, len(m.topMenuResponse.Items))
-	for i, item := range m.topMenuResponse.Items[start:end] {
-		choices[i] = fmt.Sprintf("%s %s", util.ScoreStyle.Render(util.PadRight(strconv.Itoa(item.Score), 4)), item.Title)
+	items := m.listedItems()
+	start := min((m.currentPage-1)*m.pageSize, len(items))
+	end := min(start+m.pageSize, len(items))
+	for i, item := range items[start:end] {
+		title := getItemTitle(item)
+		if m.search != nil {
+			title = getSearchResultTitle(item, m.search.terms)
+		}
+		if m.history.isRead(item.Id) {
+			title = util.ReadStyle.Render(title)
+		}
+		choices[i] = fmt.Sprintf("%s %s", util.ScoreStyle.Render(util.PadRight(strconv.Itoa(item.Score), 4)), title)
+		if n := m.history.newComments(item); n > 0 {
+			choices[i] += util.NewRepliesStyle.Render(fmt.Sprintf(" +%d new comments", n))
+		}
+		choices[i] += m.getBookmarkMarker(item.Id)
+		if m.newReplies[item.Id] {
+			choices[i] += util.NewRepliesStyle.Render(" ● new replies")
+		}
 	}
 	return choices
 
 }
 
+// getItemTitle returns the title of a story, or a one line snippet of a comment's text since comments have no title.
+func getItemTitle(item client.Item) string {
+	if item.Unavailable {
+		return util.UnavailableStyle.Render(fmt.Sprintf("[item %d unavailable]", item.Id))
+	}
+	if item.Deleted {
+		return util.UnavailableStyle.Render("[deleted]")
+	}
+	if item.Dead && item.Title == "" && item.Text == "" {
+		return util.UnavailableStyle.Render("[dead]")
+	}
+	if !item.IsComment() {
+		return item.Title
+	}
+	text := util.Truncate(strings.Join(strings.Fields(util.PlainText(item.Text)), " "), 80)
+	return util.CommentAuthorStyle.Render("comment:") + " " + text
+}
+
 // Update is called when "things happen." Its job is to look at what has happened and return an updated model in
 // response. It can also return a Cmd to make more things happen.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -96,46 +296,118 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case topMenuMsg:
+		if !m.loads.isCurrent(msg.load) {
+			// The user navigated away while this was loading
+			return m, nil
+		}
 		// The server returned a top menu response message. Save it to our model.
-		m.topMenuResponse = client.TopMenuResponse(msg)
+		m.topMenuResponse = msg.response
 		m.choices = getTopMenuCurrentPageChoices(m)
 		m.viewport.SetContent(getContent(m))
+		if m.listOptions.active() {
+			// Load the rest of the feed so we can sort and filter it
+			return m, m.RedrawPage()
+		}
 		return m, nil
 	case checkTopMenuPageMsg:
-		// We should just update the choices with the current top menu data we have.
+		if !m.loads.isCurrent(msg.load) {
+			return m, nil
+		}
+		m = m.applyTopMenuPage(msg.response)
 		m.choices = getTopMenuCurrentPageChoices(m)
 		m.viewport.SetContent(getContent(m))
 		return m, nil
 	case topicMsg:
-		item := client.Item(msg)
+		if !m.loads.isCurrent(msg.load) {
+			return m, nil
+		}
+		item := msg.item
+		if saved, ok := m.bookmarks.find(item.Id); ok && item.Incomplete() {
+			// Saved items can be read even when they, or some of their comments, aren't in the cache
+			item = saved
+		}
+		delete(m.newReplies, item.Id)
+		m.history.markRead(item, time.Now())
+		if err := m.history.save(); err != nil {
+			log.Logger.Printf("Error saving read history: %v", err)
+		}
 		if m.getCurrentTopic() == nil || m.getCurrentTopic().Id != item.Id {
 			m.topicHistoryStack = append(m.topicHistoryStack, item)
 		}
-		topic := m.getCurrentTopic()
-		// Set comments as choices
-		choices := make([]string, len(topic.Comments))
-		for i, comment := range topic.Comments {
-			commentText := util.HtmlToText(comment.Text)
-			replies := ""
-			if len(comment.Kids) > 0 {
-				replies = fmt.Sprintf(" (%d replies)", len(comment.Kids))
-			}
-			choices[i] = fmt.Sprintf(
-				"%s\n%s",
-				util.CommentAuthorStyle.Render(comment.By+replies),
-				util.CommentTextStyle.Width(m.viewport.Width-util.CommentTextStyle.GetHorizontalMargins()).
-					Render(commentText),
-			)
-		}
-		m.choices = choices
+		m = m.setTopicChoices()
+		m.viewport.SetContent(getContent(m))
+		return m, tea.Batch(tea.ClearScreen, m.stream.follow(m.client, m.getCurrentTopic().Id))
+
+	case userMsg:
+		if !m.loads.isCurrent(msg.load) {
+			return m, nil
+		}
+		user := msg.user
+		if m.profile != nil && m.profile.user.Id == user.Id && m.getCurrentTopic() == nil {
+			// Refreshing the profile we're already looking at
+			m.profile.user = user
+			m.topMenuResponse = user.SubmissionsMenuResponse()
+		} else {
+			m = m.openProfile(user)
+		}
+		m.choices = getTopMenuCurrentPageChoices(m)
 		m.viewport.SetContent(getContent(m))
-		return m, tea.ClearScreen
+		m.viewport.GotoTop()
+		return m, tea.Batch(tea.ClearScreen, m.RedrawPage())
+
+	case openedMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("opening %s: %w", msg.url, msg.err)
+		} else if msg.copied {
+			m.notice = "Couldn't open the link, copied it to the clipboard."
+		}
+		return m, nil
+
+	case bookmarkMsg:
+		m = m.addBookmark(msg.bookmark)
+		m.viewport.SetContent(getContent(m))
+		return m, nil
+
+	case globalSearchMsg:
+		if !m.loads.isCurrent(msg.load) {
+			return m, nil
+		}
+		m = m.showGlobalSearchResults(msg)
+		m.viewport.SetContent(getContent(m))
+		return m, nil
+
+	case pollUpdatesMsg:
+		return m, tea.Batch(m.PollUpdates(), scheduleUpdates())
+
+	case updatedItemsMsg:
+		m = m.applyUpdates(msg)
+		m.viewport.SetContent(getContent(m))
+		return m, nil
+
+	case streamEventMsg:
+		if !m.stream.isCurrent(msg.load) {
+			return m, nil
+		}
+		m, cmd = m.applyStreamEvent(msg)
+		m.viewport.SetContent(getContent(m))
+		return m, cmd
+
+	case streamEndedMsg:
+		if !m.stream.isCurrent(msg.load) {
+			return m, nil
+		}
+		return m, m.stream.resubscribe(m.client, msg)
 
 	case errMsg:
-		// There was an error. Note it in the model. And tell the runtime
-		// we're done and want to quit.
+		if !m.loads.isCurrent(msg.load) {
+			// Most likely the load was cancelled because the user navigated away, either way it's not wanted
+			return m, nil
+		}
+		// There was an error. Note it in the model so we can show it, but stay where we are so the user can carry
+		// on or retry it.
+		log.Logger.Printf("Error %v", msg)
 		m.err = msg
-		return m, tea.Quit
+		return m, nil
 
 	case tea.WindowSizeMsg:
 		headerHeight := lipgloss.Height(m.headerView())
@@ -158,13 +430,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.YPosition = headerHeight + 1
 			// Updatee the page size to call for more items per page if we can fit them
 			m.pageSize = m.viewport.Height - 1
-			return m, tea.Cmd(m.Init())
+			if m.client.Offline() {
+				return m, tea.Cmd(m.Init())
+			}
+			// Keep what's on screen up to date as it changes
+			return m, tea.Batch(m.Init(), scheduleUpdates())
 		} else {
 			m.viewport.Width = msg.Width
 			m.viewport.Height = msg.Height - verticalMarginHeight
 		}
 
 	case tea.KeyMsg:
+		m.notice = ""
+		if m.search != nil && m.search.typing && m.getCurrentTopic() == nil {
+			return m.updateSearchQuery(msg)
+		}
+		if m.filtering {
+			return m.updateKeywordFilter(msg)
+		}
+		if m.saving != nil {
+			return m.updateSaveDraft(msg)
+		}
 
 		// Cool, what was the actual key pressed?
 		switch msg.String() {
@@ -173,6 +459,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 
+		// Retry the command that failed, if there's an error showing
+		case "r":
+			if err, ok := m.err.(errMsg); ok && err.retry != nil {
+				m.err = nil
+				l := m.loads.start()
+				return m, func() tea.Msg { return err.retry(l) }
+			}
+
+		// Dismiss the error
+		case "esc":
+			m.err = nil
+
 		// The "up" and "k" keys move the cursor up
 		case "up", "k":
 			if m.cursor > 0 {
@@ -204,20 +502,63 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			var newTopic client.Item
 			topic := m.getCurrentTopic()
 			if topic != nil {
-				m.nextTopicId = topic.Kids[m.cursor]
+				// Focus on the thread under the cursor, which also loads any replies we left out
+				if m.cursor >= len(m.comments) {
+					break
+				}
+				m.nextTopicId = m.comments[m.cursor].comment.Id
 			} else {
 				// Viewing the top menu, clicking a topic for the first time
 				itemIndex := (m.currentPage-1)*m.pageSize + m.cursor
-				newTopic = m.topMenuResponse.Items[itemIndex]
+				items := m.listedItems()
+				if itemIndex >= len(items) {
+					break
+				}
+				newTopic = items[itemIndex]
 				m.nextTopicId = newTopic.Id
 			}
 			m.cursor = 0
 			m.viewport.GotoTop()
 			return m, tea.Cmd(m.InitTopic())
 
+		case "c":
+			if m.getCurrentTopic() != nil {
+				m = m.toggleFold()
+			}
+
+		case "C":
+			m = m.toggleFoldAll()
+
+		case "u":
+			// Open the profile of the author of the story or comment under the cursor
+			if author := m.getCursorAuthor(); author != "" {
+				return m, tea.Cmd(m.InitUser(author))
+			}
+
+		case "U":
+			// Open the profile of the author of the topic we're viewing
+			if topic := m.getCurrentTopic(); topic != nil && topic.By != "" {
+				return m, tea.Cmd(m.InitUser(topic.By))
+			}
+
 		case "backspace":
 			if m.getCurrentTopic() != nil {
 				m.topicHistoryStack = m.topicHistoryStack[:len(m.topicHistoryStack)-1]
+				if m.getCurrentTopic() == nil {
+					// Back to the menu, so there's no topic to follow. Otherwise the redraw follows the previous topic.
+					m.stream.follow(m.client, 0)
+				}
+			} else if m.profile != nil || m.search != nil {
+				if m.profile != nil {
+					m = m.closeProfile()
+				} else {
+					m = m.closeSearch()
+				}
+				if m.getCurrentTopic() == nil && m.profile == nil && m.search == nil && len(m.topMenuResponse.Items) == 0 {
+					// The top menu hadn't loaded yet when the profile or search was opened
+					m.viewport.GotoTop()
+					return m, tea.Cmd(m.Init())
+				}
 			} else {
 				// Let the user use backspace to navigate backwards as well
 				if m.currentPage > 1 {
@@ -228,7 +569,85 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.GotoTop()
 			return m, tea.Cmd(m.RedrawPage())
 
+		case "/":
+			if m.search != nil && m.getCurrentTopic() == nil {
+				// Change the query of the search we're looking at
+				m.search.typing = true
+			} else {
+				m = m.openSearch()
+			}
+			m.viewport.GotoTop()
+
+		// Open the link of the story under the cursor or the topic we're viewing, or its discussion on Hacker News
+		case "o":
+			url, _ := m.cursorLinks()
+			return m, m.openLink(url)
+		case "O":
+			_, discussion := m.cursorLinks()
+			return m, m.openLink(discussion)
+
+		// Save the story or comment under the cursor, or unsave it if it's saved
+		case "s":
+			if topic := m.getCurrentTopic(); topic != nil {
+				if m.cursor < len(m.comments) {
+					m = m.toggleBookmark(m.comments[m.cursor].comment)
+				} else {
+					m = m.toggleBookmark(*topic)
+				}
+			} else if items := m.listedItems(); (m.currentPage-1)*m.pageSize+m.cursor < len(items) {
+				m = m.toggleBookmark(items[(m.currentPage-1)*m.pageSize+m.cursor])
+			}
+
+		// Sort and filter the list we're looking at
+		case "S":
+			if m.getCurrentTopic() == nil {
+				return m.setListOptions(m.listOptions.nextSort())
+			}
+		case "M":
+			if m.getCurrentTopic() == nil {
+				return m.setListOptions(m.listOptions.nextMinScore())
+			}
+		case "H":
+			if m.getCurrentTopic() == nil {
+				options := m.listOptions
+				options.hideRead = !options.hideRead
+				return m.setListOptions(options)
+			}
+		case "F":
+			if m.getCurrentTopic() == nil {
+				m.filtering = true
+			}
+
+		case "tab", "shift+tab":
+			if m.getCurrentTopic() == nil && m.profile == nil && m.search == nil {
+				offset := 1
+				if msg.String() == "shift+tab" {
+					offset = -1
+				}
+				m = m.switchFeed(m.nextFeed(offset))
+				m.viewport.GotoTop()
+				if len(m.topMenuResponse.Items) == 0 || m.feed == feedSaved {
+					m.choices = []string{}
+					m.viewport.SetContent(getContent(m))
+					return m, tea.Cmd(m.Init())
+				}
+				return m, tea.Cmd(m.RedrawPage())
+			}
+
 		case "f5":
+			if m.search != nil && m.getCurrentTopic() == nil {
+				// Search again, to pick up anything we've seen or that's been posted since
+				if m.search.global {
+					return m, m.GlobalSearch()
+				}
+				m = m.runSearch()
+				break
+			}
+			if m.profile != nil {
+				// Reload the profile instead of the feed
+				m.currentPage = 1
+				return m, tea.Cmd(m.Init())
+			}
 			// Clear cache of top menu items
 			m.topMenuResponse = client.TopMenuResponse{}
 			// Go back to page 1
@@ -255,26 +674,47 @@ func getContent(m model) string {
 
 	topic := m.getCurrentTopic()
 
-	if topic != nil {
+	if topic != nil && topic.Unavailable {
+		s += fmt.Sprintf("%s\n\n", util.UnavailableStyle.Render(fmt.Sprintf("[item %d unavailable]", topic.Id)))
+	} else if topic != nil {
 		// Render topic view
 		if topic.Title != "" {
 			s += fmt.Sprintf("%s\n", util.TitleStyle.Render(topic.Title))
 		}
 		if topic.By != "" {
-			byLine := util.TopicAuthorStyle.Render(fmt.Sprintf("By %s (%d comments)", topic.By, len(topic.Kids)))
+			byLine := util.TopicAuthorStyle.Render(fmt.Sprintf("By %s (%d comments)", topic.By, topic.CommentCount()))
 			s += fmt.Sprintf("%s\n", byLine)
 		}
 
 		if topic.Text != "" {
-			s += fmt.Sprintf(
-				"%s\n",
-				util.TopicTextStyle.Width(m.viewport.Width-util.TopicTextStyle.GetHorizontalMargins()).
-					Render(util.HtmlToText(topic.Text)))
+			textWidth := m.viewport.Width - util.TopicTextStyle.GetHorizontalMargins()
+			s += fmt.Sprintf("%s\n", util.TopicTextStyle.Width(textWidth).Render(util.RenderHtml(topic.Text, textWidth)))
 		}
 		if topic.Url != "" {
 			s += fmt.Sprintf("→ %s\n", util.LinkStyle.Render(topic.Url))
 		}
+		if len(topic.Options) > 0 {
+			s += fmt.Sprintf("\n%s", renderPollOptions(topic.Options, m.viewport.Width))
+		}
 		s += "\n"
+	} else if m.search != nil {
+		s += m.searchHeader()
+	} else if m.profile != nil {
+		// Render profile view
+		user := m.profile.user
+		s += fmt.Sprintf("%s\n", util.TitleStyle.Render(user.Id))
+		s += fmt.Sprintf("%s\n", util.TopicAuthorStyle.Render(fmt.Sprintf(
+			"%d karma, joined %s ago", user.Karma, util.HumanizeDuration(time.Since(user.CreatedAt())))))
+		if user.About != "" {
+			textWidth := m.viewport.Width - util.TopicTextStyle.GetHorizontalMargins()
+			s += fmt.Sprintf("%s\n", util.TopicTextStyle.Width(textWidth).Render(util.RenderHtml(user.About, textWidth)))
+		}
+		s += fmt.Sprintf("\n%s\n", util.TopicAuthorStyle.Render("Submissions"))
+	} else if m.feed == feedSaved && len(m.topMenuResponse.Items) == 0 {
+		s += fmt.Sprintf("%s\n", util.UnavailableStyle.Render("Nothing saved yet, press s on a story or comment to save it"))
+	} else if m.client != nil && m.client.Offline() && len(m.topMenuResponse.Items) == 0 {
+		s += fmt.Sprintf("%s\n", util.UnavailableStyle.Render(
+			fmt.Sprintf("The %s feed isn't available offline, sync it first with: hn sync --feed %s", m.feed.Name(), m.feed.Name())))
 	}
 
 	// Iterate over our choices
@@ -307,29 +747,94 @@ func (m model) headerView() string {
 
 	// Create a breadcrumb line so people can see where they are in the navigation
 	breadCrumbLine := "──"
-	for i, topic := range m.topicHistoryStack {
-		if i > 0 {
-			breadCrumbLine += ">"
+	if m.getCurrentTopic() == nil && m.profile == nil && m.search == nil {
+		// Show the feeds as tabs so people can see which one they're on
+		for _, feed := range tabs {
+			tab := util.FeedTabStyle.Render(feed.Name())
+			if feed == m.feed {
+				tab = util.ActiveFeedTabStyle.Render(feed.Name())
+			}
+			breadCrumbLine += fmt.Sprintf(" %s ", tab)
 		}
-		breadCrumbLine += fmt.Sprintf(" %s ", topic.By)
+	} else {
+		breadCrumbLine += fmt.Sprintf(" %s ", m.feed.Name())
+		if m.profile != nil {
+			breadCrumbLine += fmt.Sprintf("> @%s ", m.profile.user.Id)
+		}
+		if m.search != nil {
+			breadCrumbLine += fmt.Sprintf("> /%s ", m.search.query)
+		}
+	}
+	for _, topic := range m.topicHistoryStack {
+		breadCrumbLine += fmt.Sprintf("> %s ", topic.By)
+	}
+	if options := m.listOptions.String(); options != "" && m.getCurrentTopic() == nil {
+		breadCrumbLine += util.ActiveFeedTabStyle.Render("["+options+"]") + " "
 	}
 	line := breadCrumbLine + strings.Repeat("─", max(0, m.viewport.Width-lipgloss.Width(title+breadCrumbLine)))
 	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
 }
 
-// footerView returns the footer view for the paginated viewport.
+// footerView returns the footer view for the paginated viewport. The badges for the state of our connection to the
+// API come before the help, so they're never cut off when the help is too long to fit.
 func (m model) footerView() string {
-	navMessage := "Press q to quit, ←/→ to paginate, F5 to refresh."
-	if m.getCurrentTopic() != nil {
+	navMessage := "Press q to quit, ←/→ to paginate, F5 to refresh, tab to switch feeds, / to search, S to sort, " +
+		"M/F/H to filter, u for profiles."
+	// Prompts come first, as they take the keys wherever they're shown
+	if m.saving != nil && m.saving.onNote {
+		navMessage = fmt.Sprintf("Note: %s█ enter to save, esc to cancel.", m.saving.note)
+	} else if m.saving != nil {
+		navMessage = fmt.Sprintf("Tag: %s█ enter to add a note, esc to cancel.", m.saving.tag)
+	} else if m.filtering {
+		navMessage = fmt.Sprintf("Filter: %s█ enter to apply, esc to clear.", m.listOptions.keyword)
+	} else if m.getCurrentTopic() != nil {
 		// Topic view
-		navMessage = "Press q to quit, backspace to go back."
+		navMessage = "Press q to quit, backspace to go back, c/C to fold, o/O to open, s to save, u/U for profiles."
+	} else if m.search != nil && m.search.typing {
+		navMessage = "Type to search, enter to browse the results, esc to cancel, tab to switch between what you've seen and all of HN."
+	} else if m.search != nil {
+		navMessage = "Press q to quit, ←/→ to paginate, backspace to go back, / to change the search."
+	} else if m.profile != nil {
+		navMessage = "Press q to quit, ←/→ to paginate, backspace to go back."
+	}
+	if m.notice != "" {
+		navMessage = util.NewRepliesStyle.Render(m.notice)
+	}
+	if m.err != nil {
+		// Show the error in place of the help, it's what the user needs to know about most
+		hint := " r to retry, esc to dismiss."
+		errText := util.Truncate(fmt.Sprintf("✗ %v", m.err), m.viewport.Width-len(hint)-20)
+		navMessage = util.ErrorStyle.Render(errText) + hint
 	}
-	navHelpLine := fmt.Sprintf("─── %s ", navMessage)
 
+	navHelpLine := "─── "
 	infoText := util.InfoBoxStyle.Render(fmt.Sprintf("Page %d", m.currentPage))
 	if m.getCurrentTopic() != nil {
 		infoText = util.InfoBoxStyle.Render(fmt.Sprintf("%3.f%%", m.viewport.ScrollPercent()*100))
 	}
-	line := navHelpLine + strings.Repeat("─", max(0, m.viewport.Width-lipgloss.Width(infoText+navHelpLine)))
+	if m.client != nil {
+		// Badges for the state of our connection to the API
+		var badges []string
+		if m.client.Offline() {
+			badges = append(badges, util.OfflineBadgeStyle.Render("OFFLINE"))
+		}
+		stats := m.client.Stats()
+		if stats.Breaker != client.BreakerClosed {
+			badges = append(badges, util.WarningBadgeStyle.Render("API PAUSED"))
+		}
+		if stats.Retries > 0 {
+			badges = append(badges, util.ScoreStyle.Render(fmt.Sprintf(" %d retries ", stats.Retries)))
+		}
+		if len(badges) > 0 {
+			navHelpLine += lipgloss.JoinHorizontal(lipgloss.Center, badges...) + " "
+		}
+	}
+	if m.ready {
+		// The help gets whatever space is left, leaving a space after it
+		room := m.viewport.Width - lipgloss.Width(infoText) - lipgloss.Width(navHelpLine) - 1
+		navMessage = truncate.StringWithTail(navMessage, uint(max(0, room)), "…")
+	}
+	navHelpLine += navMessage + " "
+	line := navHelpLine + strings.Repeat("─", max(0, m.viewport.Width-lipgloss.Width(infoText)-lipgloss.Width(navHelpLine)))
 	return lipgloss.JoinHorizontal(lipgloss.Center, line, infoText)
 }
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/dominickp/hn/client"
+	"github.com/dominickp/hn/util"
+	"github.com/muesli/termenv"
 )
 
 func Test_getTopMenuCurrentPageChoices(t *testing.T) {
@@ -53,6 +59,18 @@ func Test_getTopMenuCurrentPageChoices(t *testing.T) {
 	}
 }
 
+func Test_getItemTitle(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(termenv.Ascii)
+
+	// A long styled comment is cut short without cutting into, or leaving open, any styling
+	comment := client.Item{Type: "comment", Text: "<i>" + strings.Repeat("word ", 30) + "</i>"}
+	want := util.CommentAuthorStyle.Render("comment:") + " " + strings.Repeat("word ", 15) + "word…"
+	if got := getItemTitle(comment); got != want {
+		t.Errorf("getItemTitle() = %q, want %q", got, want)
+	}
+}
+
 func Test_model_getCurrentTopic(t *testing.T) {
 	tests := []struct {
 		name string
@@ -96,7 +114,7 @@ func Test_getContent(t *testing.T) {
 			name: "TestGetContentWithCurrentTopic",
 			args: args{m: model{
 				topicHistoryStack: []client.Item{{
-					Id: 1, Title: "item 1", By: "Joe", Kids: []int{1, 2, 3}, Url: "http://example.com", Text: "foo...",
+					Id: 1, Type: "story", Title: "item 1", By: "Joe", Kids: []int{1}, Descendants: 3, Url: "http://example.com", Text: "foo...",
 				}},
 				choices: []string{"33   item 1", "33   item 2"},
 			}},
@@ -131,3 +149,169 @@ By Joe (3 comments)
 		})
 	}
 }
+
+func Test_model_switchFeed(t *testing.T) {
+	topStories := client.TopMenuResponse{Items: []client.Item{{Id: 1}, {Id: 2}}}
+	m := model{
+		feed:            client.FeedTop,
+		feedStates:      map[client.Feed]feedState{},
+		topMenuResponse: topStories,
+		currentPage:     2,
+		cursor:          3,
+	}
+
+	m = m.switchFeed(client.FeedAsk)
+	if m.feed != client.FeedAsk || m.currentPage != 1 || m.cursor != 0 || len(m.topMenuResponse.Items) != 0 {
+		t.Errorf("switchFeed() to a new feed = %v page %d cursor %d, want a fresh feed", m.feed, m.currentPage, m.cursor)
+	}
+
+	m = m.switchFeed(client.FeedTop)
+	if m.currentPage != 2 || m.cursor != 3 || !reflect.DeepEqual(m.topMenuResponse, topStories) {
+		t.Errorf("switchFeed() back = page %d cursor %d, want the saved state restored", m.currentPage, m.cursor)
+	}
+}
+
+func Test_model_nextFeed(t *testing.T) {
+	tests := []struct {
+		name   string
+		feed   client.Feed
+		offset int
+		want   client.Feed
+	}{
+		{name: "TestNextFeed", feed: client.FeedTop, offset: 1, want: client.FeedNew},
+		{name: "TestSavedFeedIsLast", feed: client.FeedJob, offset: 1, want: feedSaved},
+		{name: "TestNextFeedWraps", feed: feedSaved, offset: 1, want: client.FeedTop},
+		{name: "TestPreviousFeedWraps", feed: client.FeedTop, offset: -1, want: feedSaved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (model{feed: tt.feed}).nextFeed(tt.offset); got != tt.want {
+				t.Errorf("model.nextFeed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_model_openProfile(t *testing.T) {
+	topMenuResponse := client.TopMenuResponse{Items: []client.Item{{Id: 1, By: "pg"}}}
+	topicHistoryStack := []client.Item{{Id: 1, By: "pg"}}
+	m := model{topMenuResponse: topMenuResponse, topicHistoryStack: topicHistoryStack, currentPage: 3, cursor: 2}
+
+	m = m.openProfile(client.User{Id: "pg", Submitted: []int{7, 8}})
+	if m.getCurrentTopic() != nil || m.currentPage != 1 || m.cursor != 0 {
+		t.Errorf("openProfile() should start at the top of the profile's first page")
+	}
+	if want := []client.Item{{Id: 7}, {Id: 8}}; !reflect.DeepEqual(m.topMenuResponse.Items, want) {
+		t.Errorf("openProfile() items = %v, want %v", m.topMenuResponse.Items, want)
+	}
+
+	m = m.closeProfile()
+	if m.profile != nil || m.currentPage != 3 || m.cursor != 2 ||
+		!reflect.DeepEqual(m.topMenuResponse, topMenuResponse) || !reflect.DeepEqual(m.topicHistoryStack, topicHistoryStack) {
+		t.Errorf("closeProfile() should restore where the profile was opened from")
+	}
+}
+
+func Test_model_UpdateErrMsg(t *testing.T) {
+	retried := false
+	retry := func(l loader) tea.Msg {
+		retried = true
+		return nil
+	}
+	m := model{topicHistoryStack: []client.Item{{Id: 1}}}
+
+	updated, cmd := m.Update(errMsg{op: "loading topic", id: 2, err: errors.New("timeout"), retry: retry})
+	m = updated.(model)
+	if cmd != nil || m.getCurrentTopic() == nil {
+		t.Fatalf("Update(errMsg) should keep the current screen")
+	}
+	if got, want := m.err.Error(), "loading topic 2: timeout"; got != want {
+		t.Errorf("Update(errMsg) err = %q, want %q", got, want)
+	}
+
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = updated.(model)
+	if m.err != nil || cmd == nil {
+		t.Fatalf("Update(r) should clear the error and retry")
+	}
+	cmd()
+	if !retried {
+		t.Errorf("Update(r) should run the failed command again")
+	}
+}
+
+func Test_model_UpdateIgnoresCancelledLoads(t *testing.T) {
+	m := model{loads: &loadTracker{}, folded: map[int]bool{}}
+	topicLoad := m.loads.start()
+	// The user backspaces before the topic loads, which starts loading the top menu instead
+	m.loads.start()
+
+	if topicLoad.ctx.Err() == nil {
+		t.Errorf("starting a new load should cancel the previous one")
+	}
+	updated, _ := m.Update(topicMsg{item: client.Item{Id: 1}, load: topicLoad.id})
+	if updated.(model).getCurrentTopic() != nil {
+		t.Errorf("Update(topicMsg) from a cancelled load should be ignored")
+	}
+}
+
+func Test_model_footerView(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		topic bool
+		want  []string
+	}{
+		{name: "TestMenu", width: 80, want: []string{"OFFLINE", "Page 1", "Press q to quit"}},
+		{name: "TestMenuWide", width: 200, want: []string{"OFFLINE", "Page 1", "F5 to refresh", "u for profiles."}},
+		{name: "TestTopic", width: 60, topic: true, want: []string{"OFFLINE", "0%", "Press q to quit"}},
+		{name: "TestNarrow", width: 30, want: []string{"OFFLINE", "Page 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := initialModel(client.New(client.WithOffline()))
+			m.ready, m.viewport.Width = true, tt.width
+			if tt.topic {
+				m.topicHistoryStack = []client.Item{{Id: 1, Title: "Topic"}}
+			}
+			got := m.footerView()
+			if lipgloss.Width(got) != tt.width {
+				t.Errorf("footerView() is %d wide, want it to fit in %d: %q", lipgloss.Width(got), tt.width, got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("footerView() = %q, want it to show %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_model_RedrawPageCopiesMenu(t *testing.T) {
+	// Nothing is cached, so the items can't be loaded while offline
+	m := initialModel(client.New(client.WithOffline()))
+	m.topMenuResponse = client.TopMenuResponse{Items: []client.Item{{Id: 1}, {Id: 2}}}
+	m.currentPage = 1
+
+	msg := m.RedrawPage()()
+	if !reflect.DeepEqual(m.topMenuResponse.Items, []client.Item{{Id: 1}, {Id: 2}}) {
+		t.Errorf("RedrawPage() changed the menu on screen from its command: %v", m.topMenuResponse.Items)
+	}
+	updated, _ := m.Update(msg)
+	if items := updated.(model).topMenuResponse.Items; !items[0].Unavailable || !items[1].Unavailable {
+		t.Errorf("Update(checkTopMenuPageMsg) = %v, want the items marked unavailable", items)
+	}
+
+	// The user pages on before the page loads
+	m.topMenuResponse = client.TopMenuResponse{Items: []client.Item{{Id: 1}, {Id: 2}}}
+	cmd := m.RedrawPage()
+	m.loads.start()
+	msg = cmd()
+	if page, ok := msg.(checkTopMenuPageMsg); !ok || page.response.Items[0].Unavailable {
+		t.Errorf("RedrawPage() of a cancelled load = %+v, want the items left with only their IDs", msg)
+	}
+	updated, _ = m.Update(msg)
+	if items := updated.(model).topMenuResponse.Items; !reflect.DeepEqual(items, []client.Item{{Id: 1}, {Id: 2}}) {
+		t.Errorf("Update(checkTopMenuPageMsg) from a cancelled load = %v, want it ignored", items)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dominickp/hn/client"
+	"github.com/dominickp/hn/util"
+)
+
+// maxPollBarWidth is how wide the bar of an option with every vote would be.
+const maxPollBarWidth = 40
+
+// renderPollOptions renders each of a poll's options with its score and a bar showing its share of the votes.
+func renderPollOptions(options []client.Item, width int) string {
+	total := client.PollVotes(options)
+	barWidth := min(maxPollBarWidth, max(width-24, 10))
+
+	s := ""
+	for _, option := range options {
+		if option.Unavailable {
+			s += fmt.Sprintf("    %s\n", util.UnavailableStyle.Render(fmt.Sprintf("[option %d unavailable]", option.Id)))
+			continue
+		}
+		share := 0.0
+		if total > 0 {
+			share = float64(option.Score) / float64(total)
+		}
+		filled := int(share*float64(barWidth) + 0.5)
+		bar := util.PollBarStyle.Render(strings.Repeat("█", filled)) + util.ScoreStyle.Render(strings.Repeat("░", barWidth-filled))
+		s += fmt.Sprintf("    %s\n", util.Truncate(strings.Join(strings.Fields(util.PlainText(option.Text)), " "), width-4))
+		s += fmt.Sprintf("    %s %s\n", bar, util.ScoreStyle.Render(fmt.Sprintf("%d points (%.0f%%)", option.Score, share*100)))
+	}
+	return s
+}
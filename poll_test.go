@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/dominickp/hn/client"
+)
+
+func Test_renderPollOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []client.Item
+		want    string
+	}{
+		{
+			name: "TestVotes",
+			options: []client.Item{
+				{Id: 2, Type: "pollopt", Text: "Yes", Score: 30},
+				{Id: 3, Type: "pollopt", Text: "No", Score: 10},
+			},
+			want: `    Yes
+    ███████████████░░░░░ 30 points (75%)
+    No
+    █████░░░░░░░░░░░░░░░ 10 points (25%)
+`,
+		},
+		{
+			name: "TestNoVotes",
+			options: []client.Item{
+				{Id: 2, Type: "pollopt", Text: "Yes"},
+				{Id: 3, Unavailable: true},
+			},
+			want: `    Yes
+    ░░░░░░░░░░░░░░░░░░░░ 0 points (0%)
+    [option 3 unavailable]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderPollOptions(tt.options, 44); got != tt.want {
+				t.Errorf("renderPollOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dominickp/hn/util"
+)
+
+// runSaved implements the saved command. Its only subcommand is export, which writes the bookmarks to out as JSON or
+// Markdown.
+func runSaved(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "export" {
+		return errors.New("usage: hn saved export [--format json|markdown]")
+	}
+	flags := flag.NewFlagSet("saved export", flag.ExitOnError)
+	format := flags.String("format", "json", "format to export bookmarks in: json or markdown")
+	flags.Parse(args[1:])
+
+	bookmarks, err := openConfigFile("bookmarks.json", loadBookmarks)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "json":
+		return exportBookmarksJSON(bookmarks.bookmarks, out)
+	case "markdown", "md":
+		return exportBookmarksMarkdown(bookmarks.bookmarks, out)
+	}
+	return fmt.Errorf("unknown format %q, expected json or markdown", *format)
+}
+
+// exportBookmarksJSON writes bookmarks as an indented JSON array, items and their comments included.
+func exportBookmarksJSON(bookmarks []bookmark, out io.Writer) error {
+	if bookmarks == nil {
+		bookmarks = []bookmark{}
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bookmarks)
+}
+
+// exportBookmarksMarkdown writes bookmarks as a Markdown list, one entry per bookmark with a link to its discussion.
+func exportBookmarksMarkdown(bookmarks []bookmark, out io.Writer) error {
+	var s strings.Builder
+	s.WriteString("# Saved from Hacker News\n")
+	for _, b := range bookmarks {
+		item := b.Item
+		discussion := item.DiscussionUrl()
+		s.WriteString("\n")
+		if item.IsComment() {
+			text := util.Truncate(strings.Join(strings.Fields(util.PlainText(item.Text)), " "), 200)
+			fmt.Fprintf(&s, "- [Comment by %s](%s): %s\n", item.By, discussion, text)
+		} else {
+			link := item.Url
+			if link == "" {
+				link = discussion
+			}
+			fmt.Fprintf(&s, "- [%s](%s) by %s, %d points", item.Title, link, item.By, item.Score)
+			if item.Url != "" {
+				fmt.Fprintf(&s, ", [discussion](%s)", discussion)
+			}
+			s.WriteString("\n")
+		}
+		fmt.Fprintf(&s, "  - Saved %s", b.SavedAt.Format(time.DateOnly))
+		if b.Tag != "" {
+			fmt.Fprintf(&s, ", tagged `%s`", b.Tag)
+		}
+		s.WriteString("\n")
+		if b.Note != "" {
+			fmt.Fprintf(&s, "  - %s\n", b.Note)
+		}
+	}
+	_, err := io.WriteString(out, s.String())
+	return err
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
+	"github.com/dominickp/hn/util"
+)
+
+// maxSearchResults is how many results a search lists.
+const maxSearchResults = 200
+
+// searchView is a search of the stories and comments we've seen. While it's open, its results are listed in place of
+// the top menu.
+type searchView struct {
+	query    string
+	typing   bool     // Whether keys go to the query rather than the results
+	global   bool     // Whether we're searching all of Hacker News, rather than just what we've seen
+	terms    []string // The words the results matched, for highlighting
+	previous navState // Where the search was opened from
+}
+
+// openSearch starts a new search, remembering where we opened it from. Whatever was loading for the screen we were
+// on is cancelled, so it can't replace the results when it arrives.
+func (m model) openSearch() model {
+	m.loads.start()
+	m.search = &searchView{typing: true, previous: m.navState()}
+	m.topMenuResponse = client.TopMenuResponse{}
+	m.choices = []string{}
+	m.currentPage = 1
+	m.cursor = 0
+	m.topicHistoryStack = nil
+	m.profile = nil
+	return m
+}
+
+// closeSearch takes us back to wherever the current search was opened from.
+func (m model) closeSearch() model {
+	return m.restoreNavState(m.search.previous)
+}
+
+// runSearch lists the results of the current query. Searches of what we've seen are quick enough to run as the user
+// types, global searches are only run when they ask for one.
+func (m model) runSearch() model {
+	if m.search.global {
+		return m
+	}
+	results := m.client.Search(m.search.query, maxSearchResults)
+	items := make([]client.Item, len(results))
+	m.search.terms = nil
+	for i, result := range results {
+		items[i] = result.Item
+		m.search.terms = result.Terms
+	}
+	m.topMenuResponse = client.TopMenuResponse{Items: items}
+	m.currentPage = 1
+	m.cursor = 0
+	m.choices = getTopMenuCurrentPageChoices(m)
+	return m
+}
+
+// updateSearchQuery handles keys while the user is typing a query, searching as they type.
+func (m model) updateSearchQuery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m = m.closeSearch()
+		m.viewport.GotoTop()
+		return m, m.RedrawPage()
+	case tea.KeyTab:
+		m.search.global = !m.search.global
+		m.search.terms = nil
+		m.topMenuResponse = client.TopMenuResponse{}
+		m.choices = []string{}
+		m = m.runSearch()
+	case tea.KeyEnter:
+		// Browse the results
+		m.search.typing = false
+		if m.search.global && strings.TrimSpace(m.search.query) != "" {
+			m.viewport.SetContent(getContent(m))
+			return m, m.GlobalSearch()
+		}
+	default:
+		if query, edited := editLine(m.search.query, msg); edited {
+			m.search.query = query
+			m = m.runSearch()
+		}
+	}
+	m.viewport.SetContent(getContent(m))
+	return m, nil
+}
+
+// GlobalSearch searches all of Hacker News for the current query.
+func (m model) GlobalSearch() tea.Cmd {
+	l := m.loads.start()
+	query := m.search.query
+	return func() tea.Msg {
+		return checkGlobalSearch(l, m.client, query)
+	}
+}
+
+// showGlobalSearchResults lists the results of a global search, if it's for the query we're looking at.
+func (m model) showGlobalSearchResults(msg globalSearchMsg) model {
+	if m.search == nil || !m.search.global || m.search.query != msg.query || m.getCurrentTopic() != nil {
+		return m
+	}
+	m.topMenuResponse = msg.response
+	m.search.terms = strings.Fields(strings.ToLower(msg.query))
+	m.currentPage = 1
+	m.cursor = 0
+	m.choices = getTopMenuCurrentPageChoices(m)
+	return m
+}
+
+// editLine applies a key to a line the user is typing, reporting whether it was a key that edits the line.
+func editLine(line string, msg tea.KeyMsg) (string, bool) {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		runes := []rune(line)
+		return string(runes[:max(0, len(runes)-1)]), true
+	case tea.KeyRunes, tea.KeySpace:
+		return line + string(msg.Runes), true
+	}
+	return line, false
+}
+
+// getSearchResultTitle returns the title of a story, or a snippet of a comment around where it matched, with the
+// search terms highlighted.
+func getSearchResultTitle(item client.Item, terms []string) string {
+	if !item.IsComment() {
+		return highlightTerms(item.Title, terms)
+	}
+	text := strings.Join(strings.Fields(util.PlainText(item.Text)), " ")
+	return util.CommentAuthorStyle.Render(item.By+":") + " " + highlightTerms(snippet(text, terms, 80), terms)
+}
+
+// snippet returns up to width characters of text, starting from a word a little before the first of the terms.
+func snippet(text string, terms []string, width int) string {
+	runes := []rune(text)
+	start, _ := findTerm(runes, terms, 0)
+	if start <= width/3 {
+		return util.Truncate(text, width)
+	}
+	from := start - width/3
+	for from < start && runes[from-1] != ' ' {
+		from++
+	}
+	return util.Truncate("…"+string(runes[from:]), width)
+}
+
+// highlightTerms highlights every occurrence of the terms in text, ignoring case.
+func highlightTerms(text string, terms []string) string {
+	runes := []rune(text)
+	s := ""
+	for from := 0; from < len(runes); {
+		start, end := findTerm(runes, terms, from)
+		if start < 0 {
+			s += string(runes[from:])
+			break
+		}
+		s += string(runes[from:start]) + util.SearchMatchStyle.Render(string(runes[start:end]))
+		from = end
+	}
+	return s
+}
+
+// findTerm returns where the first occurrence of any of the terms is in text at or after from, ignoring case, or -1
+// if there isn't one.
+func findTerm(text []rune, terms []string, from int) (start, end int) {
+	lower := []rune(strings.ToLower(string(text)))
+	if len(lower) != len(text) {
+		// Lower casing changed the length, so positions wouldn't line up
+		lower = text
+	}
+	for i := from; i < len(lower); i++ {
+		for _, term := range terms {
+			termRunes := []rune(term)
+			if len(termRunes) > 0 && i+len(termRunes) <= len(lower) && string(lower[i:i+len(termRunes)]) == term {
+				return i, i + len(termRunes)
+			}
+		}
+	}
+	return -1, -1
+}
+
+// searchHeader returns the search prompt and a summary of the results, shown above them.
+func (m model) searchHeader() string {
+	prompt := util.TitleStyle.Render("/ " + m.search.query)
+	if m.search.typing {
+		prompt += util.CursorStyle.Render("█")
+	}
+	var summary string
+	switch {
+	case m.search.global && strings.TrimSpace(m.search.query) == "":
+		summary = "Search all of Hacker News, press tab to search just what you've seen"
+	case m.search.global && len(m.topMenuResponse.Items) == 0:
+		summary = "Press enter to search all of Hacker News"
+	case m.search.global:
+		summary = fmt.Sprintf("%d results from all of Hacker News", len(m.topMenuResponse.Items))
+	case strings.TrimSpace(m.search.query) == "":
+		summary = "Search the stories and comments you've seen by title, text, author or site, press tab to search all of Hacker News"
+	case len(m.topMenuResponse.Items) == 0:
+		summary = "Nothing you've seen matches"
+	default:
+		summary = fmt.Sprintf("%d results from the stories and comments you've seen", len(m.topMenuResponse.Items))
+	}
+	return fmt.Sprintf("%s\n%s\n\n", prompt, util.TopicAuthorStyle.Render(summary))
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dominickp/hn/client"
+	"github.com/dominickp/hn/util"
+	"github.com/muesli/termenv"
+)
+
+func Test_model_search(t *testing.T) {
+	index := client.NewIndex()
+	index.Add(client.Item{Id: 1, Type: "story", Title: "Go 1.22 is released", Score: 10})
+	index.Add(client.Item{Id: 2, Type: "comment", By: "rsc", Text: "Range over func is coming in Go 1.23"})
+	index.Add(client.Item{Id: 3, Type: "story", Title: "Rust 1.76 is released", Score: 20})
+	topMenuResponse := client.TopMenuResponse{Items: []client.Item{{Id: 9, Type: "story"}}}
+	m := initialModel(client.New(client.WithIndex(index)))
+	m.topMenuResponse, m.currentPage, m.cursor = topMenuResponse, 2, 1
+
+	m = m.openSearch()
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("go")},
+		{Type: tea.KeySpace, Runes: []rune(" ")},
+		{Type: tea.KeyRunes, Runes: []rune("1.2x")},
+		{Type: tea.KeyBackspace},
+	} {
+		updated, _ := m.Update(key)
+		m = updated.(model)
+	}
+	if m.search.query != "go 1.2" {
+		t.Errorf("search query = %q, want %q", m.search.query, "go 1.2")
+	}
+	var ids []int
+	for _, item := range m.topMenuResponse.Items {
+		ids = append(ids, item.Id)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("search results = %v, want %v", ids, want)
+	}
+
+	// Enter moves from the query to the results, so keys work as usual again
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = updated.(model)
+	if m.search.typing || m.search.query != "go 1.2" || m.cursor != 1 {
+		t.Errorf("after enter, j should move the cursor rather than change the query")
+	}
+
+	m = m.closeSearch()
+	if m.search != nil || m.currentPage != 2 || m.cursor != 1 || !reflect.DeepEqual(m.topMenuResponse, topMenuResponse) {
+		t.Errorf("closeSearch() should restore where the search was opened from")
+	}
+}
+
+func Test_snippet(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog and keeps on running"
+	tests := []struct {
+		name  string
+		terms []string
+		width int
+		want  string
+	}{
+		{name: "TestMatchNearStart", terms: []string{"quick"}, width: 20, want: "The quick brown fox…"},
+		{name: "TestMatchLater", terms: []string{"lazy"}, width: 20, want: "…the lazy dog and k…"},
+		{name: "TestNoMatch", terms: []string{"cat"}, width: 20, want: "The quick brown fox…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet(text, tt.terms, tt.width); got != tt.want {
+				t.Errorf("snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getSearchResultTitle(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(termenv.Ascii)
+
+	// The terms are matched against the comment's text, never the escape codes styling it
+	comment := client.Item{Type: "comment", By: "rsc", Text: "<i>Hmm</i>, see <a href=\"https://go.dev\">the docs</a>"}
+	got := getSearchResultTitle(comment, []string{"m"})
+	want := util.CommentAuthorStyle.Render("rsc:") + " H" + util.SearchMatchStyle.Render("m") + util.SearchMatchStyle.Render("m") +
+		", see https://go.dev"
+	if got != want {
+		t.Errorf("getSearchResultTitle() = %q, want %q", got, want)
+	}
+}
+
+func Test_findTerm(t *testing.T) {
+	text := []rune("Ünïcode and GO, go!")
+	if start, end := findTerm(text, []string{"go"}, 0); start != 12 || end != 14 {
+		t.Errorf("findTerm() = %d, %d, want 12, 14", start, end)
+	}
+	if start, end := findTerm(text, []string{"go"}, 13); start != 16 || end != 18 {
+		t.Errorf("findTerm() from 13 = %d, %d, want 16, 18", start, end)
+	}
+	if start, _ := findTerm(text, []string{"rust"}, 0); start != -1 {
+		t.Errorf("findTerm() = %d, want -1", start)
+	}
+}
+
+func Test_model_showGlobalSearchResults(t *testing.T) {
+	m := initialModel(client.New(client.WithOffline()))
+	m = m.openSearch()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Go")})
+	m = updated.(model)
+	if !m.search.global || len(m.topMenuResponse.Items) != 0 {
+		t.Fatalf("global searches shouldn't run as the user types")
+	}
+
+	response := client.TopMenuResponse{Items: []client.Item{{Id: 1, Type: "story", Title: "Go"}}}
+	if got := m.showGlobalSearchResults(globalSearchMsg{query: "Rust", response: response}); len(got.topMenuResponse.Items) != 0 {
+		t.Errorf("showGlobalSearchResults() should ignore results for an old query")
+	}
+	m = m.showGlobalSearchResults(globalSearchMsg{query: "Go", response: response})
+	if !reflect.DeepEqual(m.topMenuResponse, response) || !reflect.DeepEqual(m.search.terms, []string{"go"}) {
+		t.Errorf("showGlobalSearchResults() = %v with terms %v, want the results listed", m.topMenuResponse, m.search.terms)
+	}
+}
+
+func Test_model_openSearchCancelsLoad(t *testing.T) {
+	m := initialModel(client.New(client.WithOffline()))
+	feedLoad := m.loads.start()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = updated.(model)
+
+	// The feed arrives after the search was opened
+	response := client.TopMenuResponse{Items: []client.Item{{Id: 1, Type: "story", Title: "Feed story"}}}
+	updated, _ = m.Update(topMenuMsg{response: response, load: feedLoad.id})
+	m = updated.(model)
+	if feedLoad.ctx.Err() == nil {
+		t.Errorf("opening a search should cancel what was loading")
+	}
+	if m.search == nil || len(m.topMenuResponse.Items) != 0 || len(m.choices) != 0 {
+		t.Errorf("the feed should be ignored once the search is open, got %v", m.topMenuResponse.Items)
+	}
+	updated, _ = m.Update(checkTopMenuPageMsg{load: feedLoad.id})
+	if len(updated.(model).choices) != 0 {
+		t.Errorf("checkTopMenuPageMsg from the feed load should be ignored")
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
+	log "github.com/dominickp/hn/logger"
+)
+
+// maxStreamRetryDelay caps how long we wait before resubscribing to a stream which keeps dropping.
+const maxStreamRetryDelay = time.Minute
+
+// itemStream follows live changes to the topic on screen, when streaming is turned on.
+type itemStream struct {
+	itemId int
+	loads  loadTracker                      // The subscription, cancelled when we follow another item
+	delay  func(failures int) time.Duration // How long to wait before resubscribing, nil for streamRetryDelay
+}
+
+// streamEventMsg carries a change to the item being streamed.
+type streamEventMsg struct {
+	itemId   int
+	event    client.StreamEvent
+	events   <-chan client.StreamEvent
+	load     int
+	failures int // How many times in a row the subscription had ended without any changes before this one
+}
+
+// streamEndedMsg says a subscription couldn't be made, or dropped, e.g. when Firebase closes the connection.
+type streamEndedMsg struct {
+	itemId   int
+	load     int
+	failures int // How many times in a row the subscription has ended without any changes coming through
+}
+
+// follow subscribes to changes to an item, ending any previous subscription. An item ID of 0 just ends it.
+func (s *itemStream) follow(c *client.Client, itemId int) tea.Cmd {
+	if s == nil || s.itemId == itemId {
+		return nil
+	}
+	s.itemId = itemId
+	if itemId == 0 {
+		s.stop()
+		return nil
+	}
+	return s.subscribe(c, itemId, 0)
+}
+
+// resubscribe subscribes again to the item a subscription which ended was for, after a delay growing with the
+// number of times in a row it's ended. Polling keeps the item up to date in the meantime, just less promptly.
+func (s *itemStream) resubscribe(c *client.Client, msg streamEndedMsg) tea.Cmd {
+	if s == nil || s.itemId != msg.itemId {
+		return nil
+	}
+	return s.subscribe(c, msg.itemId, msg.failures)
+}
+
+// subscribe starts a subscription to an item, waiting first if it's failed before.
+func (s *itemStream) subscribe(c *client.Client, itemId int, failures int) tea.Cmd {
+	l := s.loads.start()
+	var delay time.Duration
+	if failures > 0 {
+		delay = streamRetryDelay(failures)
+		if s.delay != nil {
+			delay = s.delay(failures)
+		}
+	}
+	return func() tea.Msg {
+		select {
+		case <-l.ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		events, err := c.SubscribeItem(l.ctx, itemId)
+		if err != nil {
+			log.Logger.Printf("Error streaming item %d: %v", itemId, err)
+			return streamEndedMsg{itemId: itemId, load: l.id, failures: failures + 1}
+		}
+		return waitForStreamEvent(itemId, events, l.id, failures)
+	}
+}
+
+// streamRetryDelay doubles from a second with each failure, up to maxStreamRetryDelay.
+func streamRetryDelay(failures int) time.Duration {
+	if failures > 6 {
+		return maxStreamRetryDelay
+	}
+	return min(time.Second<<(failures-1), maxStreamRetryDelay)
+}
+
+// stop ends the subscription, if there is one.
+func (s *itemStream) stop() {
+	if s.loads.cancel != nil {
+		s.loads.cancel()
+		s.loads.cancel = nil
+	}
+	s.loads.id++
+}
+
+// isCurrent reports whether events from the subscription with the given ID are still wanted.
+func (s *itemStream) isCurrent(load int) bool {
+	return s != nil && s.loads.isCurrent(load)
+}
+
+// waitForStreamEvent waits for the next change on a subscription, or for the stream to end. failures is how many
+// times in a row the subscription had ended without any changes coming through before this one.
+func waitForStreamEvent(itemId int, events <-chan client.StreamEvent, load int, failures int) tea.Msg {
+	event, ok := <-events
+	if !ok {
+		return streamEndedMsg{itemId: itemId, load: load, failures: failures + 1}
+	}
+	return streamEventMsg{itemId: itemId, event: event, events: events, load: load, failures: failures}
+}
+
+// applyStreamEvent applies a streamed change to the topic it's for, then waits for the next one.
+func (m model) applyStreamEvent(msg streamEventMsg) (model, tea.Cmd) {
+	failures := msg.failures
+	if msg.event.Type == "put" || msg.event.Type == "patch" {
+		failures = 0
+	}
+	next := func() tea.Msg {
+		return waitForStreamEvent(msg.itemId, msg.events, msg.load, failures)
+	}
+	topic := m.getCurrentTopic()
+	if topic == nil || topic.Id != msg.itemId {
+		return m, next
+	}
+	updated, err := msg.event.ApplyTo(*topic)
+	if err != nil {
+		log.Logger.Printf("Error applying streamed change to item %d: %v", msg.itemId, err)
+		return m, next
+	}
+	return m.applyUpdates(updatedItemsMsg{items: []client.Item{updated}}), next
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	stdlog "log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dominickp/hn/client"
+)
+
+func Test_model_applyStreamEvent(t *testing.T) {
+	events := make(chan client.StreamEvent)
+	close(events)
+	m := model{
+		topicHistoryStack: []client.Item{{Id: 1, Score: 10, Kids: []int{2}, Comments: []client.Item{{Id: 2, Text: "comment"}}}},
+		folded:            map[int]bool{},
+		newReplies:        map[int]bool{},
+		stream:            &itemStream{},
+	}
+	m = m.setTopicChoices()
+
+	m, cmd := m.applyStreamEvent(streamEventMsg{
+		itemId: 1,
+		event:  client.StreamEvent{Type: "patch", Path: "/", Data: json.RawMessage(`{"score":11,"kids":[2,3]}`)},
+		events: events,
+	})
+	topic := m.getCurrentTopic()
+	if topic.Score != 11 || len(topic.Comments) != 1 {
+		t.Errorf("applyStreamEvent() = %+v, want the score updated and the loaded comments kept", topic)
+	}
+	if !m.newReplies[1] {
+		t.Errorf("applyStreamEvent() should mark the topic as having new replies")
+	}
+	if ended, ok := cmd().(streamEndedMsg); !ok || ended.failures != 1 {
+		t.Errorf("applyStreamEvent() should wait for the next event, and say when the stream ends")
+	}
+}
+
+func Test_itemStream_resubscribe(t *testing.T) {
+	// The server sends one change then drops the connection, as Firebase does from time to time
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 2 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: put\ndata: {\"path\":\"/\",\"data\":{\"id\":1,\"score\":5}}\n\n")
+	}))
+	defer server.Close()
+	c := client.New(client.WithBaseURL(server.URL+"/v0"), client.WithLogger(stdlog.New(io.Discard, "", 0)))
+
+	var delays []int
+	m := model{
+		topicHistoryStack: []client.Item{{Id: 1, Score: 4}},
+		folded:            map[int]bool{},
+		newReplies:        map[int]bool{},
+		stream: &itemStream{delay: func(failures int) time.Duration {
+			delays = append(delays, failures)
+			return 0
+		}},
+	}
+	m = m.setTopicChoices()
+
+	// Subscribe, get the change, then see the stream drop
+	event, ok := m.stream.follow(c, 1)().(streamEventMsg)
+	if !ok {
+		t.Fatalf("follow() should stream the item's changes")
+	}
+	m, cmd := m.applyStreamEvent(event)
+	ended, ok := cmd().(streamEndedMsg)
+	if !ok || ended.failures != 1 || !m.stream.isCurrent(ended.load) {
+		t.Fatalf("applyStreamEvent() = %+v, want the stream to end after one change", ended)
+	}
+
+	// Subscribing again fails, so the next try waits longer
+	ended, ok = m.stream.resubscribe(c, ended)().(streamEndedMsg)
+	if !ok || ended.failures != 2 || !m.stream.isCurrent(ended.load) {
+		t.Fatalf("resubscribe() = %+v, want the subscription to fail", ended)
+	}
+
+	// Then it works again
+	event, ok = m.stream.resubscribe(c, ended)().(streamEventMsg)
+	if !ok || event.load != ended.load+1 {
+		t.Fatalf("resubscribe() = %+v, want the item's changes streamed again", event)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("subscribed %d times, want 3", got)
+	}
+	if !reflect.DeepEqual(delays, []int{1, 2}) {
+		t.Errorf("waited after %v failures, want [1 2]", delays)
+	}
+
+	// Once we follow something else, a late end to the subscription is ignored
+	m.stream.follow(c, 0)
+	if m.stream.isCurrent(event.load) || m.stream.resubscribe(c, streamEndedMsg{itemId: 1, load: event.load}) != nil {
+		t.Errorf("resubscribe() should do nothing once we've stopped following the item")
+	}
+}
+
+func Test_streamRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "TestFirst", failures: 1, want: time.Second},
+		{name: "TestDoubles", failures: 3, want: 4 * time.Second},
+		{name: "TestCapped", failures: 7, want: time.Minute},
+		{name: "TestManyFailures", failures: 100, want: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streamRetryDelay(tt.failures); got != tt.want {
+				t.Errorf("streamRetryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/dominickp/hn/client"
+)
+
+// runSync implements the sync command, which stores a feed's stories and their comments in the cache so they can be
+// read with --offline.
+func runSync(args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	feedName := flags.String("feed", "top", "feed to sync: top, new, best, ask, show or job")
+	depth := flags.Int("depth", 3, "levels of comments to sync beneath each story, 0 for all of them")
+	limit := flags.Int("limit", 100, "number of stories to sync from the top of the feed, 0 for all of them")
+	flags.Parse(args)
+
+	feed, err := client.ParseFeed(*feedName)
+	if err != nil {
+		return err
+	}
+	cache, err := openCache()
+	if err != nil {
+		return err
+	}
+	c := client.New(client.WithCache(cache))
+	defer c.Close()
+
+	// Stop cleanly on ctrl+c, so the next sync can resume from the last story we finished
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := client.SyncOptions{Feed: feed, Depth: *depth, Limit: *limit}
+	return c.Sync(ctx, opts, func(p client.SyncProgress) {
+		width := len(fmt.Sprint(p.Total))
+		prefix := fmt.Sprintf("[%*d/%d]", width, p.Done, p.Total)
+		switch {
+		case p.Skipped:
+			fmt.Printf("%s %d already synced\n", prefix, p.Story.Id)
+		case p.Err != nil:
+			fmt.Printf("%s %d failed: %v\n", prefix, p.Story.Id, p.Err)
+		default:
+			fmt.Printf("%s %s (%d comments)\n", prefix, p.Story.Title, p.Comments)
+		}
+	})
+}
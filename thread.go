@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dominickp/hn/client"
+	"github.com/dominickp/hn/util"
+)
+
+// commentRow is a comment in the flattened, threaded view of a topic's discussion.
+type commentRow struct {
+	comment    client.Item
+	depth      int    // How deeply the comment is nested, 0 being a direct reply to the topic
+	folded     bool   // Whether the comment's thread is folded away beneath it
+	newReplies bool   // Whether the comment has had new replies since we loaded it
+	bookmark   string // The marker shown beside the comment if it's saved
+}
+
+// flattenComments walks a comment tree depth first, returning the comments in reading order. The replies of comments
+// in folded are left out.
+func flattenComments(comments []client.Item, depth int, folded map[int]bool) []commentRow {
+	var rows []commentRow
+	for _, comment := range comments {
+		if folded[comment.Id] && hasReplies(comment) {
+			rows = append(rows, commentRow{comment: comment, depth: depth, folded: true})
+			continue
+		}
+		rows = append(rows, commentRow{comment: comment, depth: depth})
+		rows = append(rows, flattenComments(comment.Comments, depth+1, folded)...)
+	}
+	return rows
+}
+
+// hasReplies reports whether a comment has any replies, loaded or not.
+func hasReplies(comment client.Item) bool {
+	return len(comment.Comments) > 0 || len(comment.Kids) > 0
+}
+
+// countReplies returns the number of replies beneath a comment, counting those we haven't loaded where we know of them.
+func countReplies(comment client.Item) int {
+	if len(comment.Comments) == 0 {
+		return len(comment.Kids)
+	}
+	count := 0
+	for _, reply := range comment.Comments {
+		count += 1 + countReplies(reply)
+	}
+	return count
+}
+
+// toggleFold folds the thread under the cursor, or unfolds it if it's already folded.
+func (m model) toggleFold() model {
+	if m.cursor >= len(m.comments) || !hasReplies(m.comments[m.cursor].comment) {
+		return m
+	}
+	id := m.comments[m.cursor].comment.Id
+	if m.folded[id] {
+		delete(m.folded, id)
+	} else {
+		m.folded[id] = true
+	}
+	// Rows above the cursor don't change, so the cursor stays on the same comment
+	return m.setTopicChoices()
+}
+
+// toggleFoldAll folds every top level thread of the current topic, or unfolds them all if they're already folded.
+// The cursor moves to the top level thread it was in.
+func (m model) toggleFoldAll() model {
+	topic := m.getCurrentTopic()
+	if topic == nil {
+		return m
+	}
+
+	cursorThreadId := 0
+	for i := min(m.cursor, len(m.comments)-1); i >= 0; i-- {
+		if m.comments[i].depth == 0 {
+			cursorThreadId = m.comments[i].comment.Id
+			break
+		}
+	}
+
+	allFolded := true
+	for _, comment := range topic.Comments {
+		if hasReplies(comment) && !m.folded[comment.Id] {
+			allFolded = false
+			break
+		}
+	}
+	for _, comment := range topic.Comments {
+		if allFolded {
+			delete(m.folded, comment.Id)
+		} else if hasReplies(comment) {
+			m.folded[comment.Id] = true
+		}
+	}
+
+	m = m.setTopicChoices()
+	for i, row := range m.comments {
+		if row.comment.Id == cursorThreadId {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// renderCommentRow renders a comment indented by its depth, with guides down the left showing which thread it
+// belongs to.
+func renderCommentRow(row commentRow, width int) string {
+	guide := strings.Repeat(util.ThreadGuideStyle.Render("│ "), row.depth)
+	if row.comment.Unavailable {
+		return guide + util.UnavailableStyle.Render(fmt.Sprintf("[comment %d unavailable]", row.comment.Id))
+	}
+	if row.folded {
+		return guide + util.CommentAuthorStyle.Render(fmt.Sprintf("%s — %d hidden replies", row.comment.By, countReplies(row.comment)))
+	}
+
+	replies := ""
+	if len(row.comment.Comments) == 0 && len(row.comment.Kids) > 0 {
+		replies = fmt.Sprintf(" (%d more replies)", len(row.comment.Kids))
+	}
+	textWidth := max(20, width-2*row.depth-util.CommentTextStyle.GetHorizontalMargins())
+	text := util.CommentTextStyle.Width(textWidth).Render(util.RenderHtml(row.comment.Text, textWidth))
+
+	author := util.CommentAuthorStyle.Render(row.comment.By + replies)
+	if row.newReplies {
+		author += util.NewRepliesStyle.Render(" ● new replies")
+	}
+	author += row.bookmark
+
+	// The first line sits next to the cursor, so indent the rest to line the guides up beneath it
+	lines := []string{guide + author}
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, "  "+guide+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setTopicChoices sets the comments of the current topic as our choices.
+func (m model) setTopicChoices() model {
+	topic := m.getCurrentTopic()
+	if topic == nil {
+		return m
+	}
+	m.comments = flattenComments(topic.Comments, 0, m.folded)
+	m.choices = make([]string, len(m.comments))
+	for i := range m.comments {
+		m.comments[i].newReplies = m.newReplies[m.comments[i].comment.Id]
+		m.comments[i].bookmark = m.getBookmarkMarker(m.comments[i].comment.Id)
+		m.choices[i] = renderCommentRow(m.comments[i], m.viewport.Width-2)
+	}
+	return m
+}
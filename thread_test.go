@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dominickp/hn/client"
+)
+
+func Test_flattenComments(t *testing.T) {
+	comments := []client.Item{
+		{Id: 1, Comments: []client.Item{
+			{Id: 2, Comments: []client.Item{{Id: 3}}},
+			{Id: 4},
+		}},
+		{Id: 5},
+	}
+
+	var got []string
+	for _, row := range flattenComments(comments, 0, map[int]bool{4: true, 5: true}) {
+		got = append(got, fmt.Sprintf("%d@%d", row.comment.Id, row.depth))
+	}
+
+	// Only comments with replies can be folded
+	want := []string{"1@0", "2@1", "3@2", "4@1", "5@0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenComments() = %v, want %v", got, want)
+	}
+}
+
+// trimLineEnds strips the padding lipgloss adds to the end of each line.
+func trimLineEnds(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func Test_renderCommentRow(t *testing.T) {
+	tests := []struct {
+		name string
+		row  commentRow
+		want string
+	}{
+		{
+			name: "TestTopLevelComment",
+			row:  commentRow{comment: client.Item{By: "pg", Text: "hello"}},
+			want: "pg\n      hello\n",
+		},
+		{
+			name: "TestNestedCommentWithUnloadedReplies",
+			row:  commentRow{comment: client.Item{By: "pg", Text: "hello", Kids: []int{1, 2}}, depth: 2},
+			want: "│ │ pg (2 more replies)\n  │ │     hello\n  │ │",
+		},
+		{
+			name: "TestFoldedComment",
+			row:  commentRow{comment: client.Item{By: "pg", Text: "hello", Kids: []int{1, 2}}, depth: 1, folded: true},
+			want: "│ pg — 2 hidden replies",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimLineEnds(renderCommentRow(tt.row, 40)); got != tt.want {
+				t.Errorf("renderCommentRow() = \n'%v', want \n'%v'", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_flattenCommentsFolded(t *testing.T) {
+	comments := []client.Item{
+		{Id: 1, Comments: []client.Item{{Id: 2, Comments: []client.Item{{Id: 3}}}}},
+		{Id: 4, Comments: []client.Item{{Id: 5}}},
+	}
+
+	rows := flattenComments(comments, 0, map[int]bool{2: true})
+	var got []string
+	for _, row := range rows {
+		got = append(got, fmt.Sprintf("%d@%d %v", row.comment.Id, row.depth, row.folded))
+	}
+
+	want := []string{"1@0 false", "2@1 true", "4@0 false", "5@1 false"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenComments() = %v, want %v", got, want)
+	}
+}
+
+func Test_model_toggleFoldAll(t *testing.T) {
+	topic := client.Item{Id: 1, Comments: []client.Item{
+		{Id: 2, Comments: []client.Item{{Id: 3}}},
+		{Id: 4, Comments: []client.Item{{Id: 5}}},
+		{Id: 6},
+	}}
+	m := model{topicHistoryStack: []client.Item{topic}, folded: map[int]bool{}}
+	m = m.setTopicChoices()
+	m.cursor = 3 // Comment 5, in the thread of comment 4
+
+	m = m.toggleFoldAll()
+	if len(m.comments) != 3 || m.cursor != 1 {
+		t.Errorf("toggleFoldAll() = %d rows with cursor %d, want 3 rows with cursor 1", len(m.comments), m.cursor)
+	}
+
+	m = m.toggleFoldAll()
+	if len(m.comments) != 5 || len(m.folded) != 0 {
+		t.Errorf("toggleFoldAll() again = %d rows, want every thread unfolded", len(m.comments))
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dominickp/hn/client"
+	log "github.com/dominickp/hn/logger"
+)
+
+// updatesInterval is how often we ask the API what's changed.
+const updatesInterval = time.Minute
+
+// pollUpdatesMsg is sent when it's time to check for updates.
+type pollUpdatesMsg struct{}
+
+// updatedItemsMsg carries fresh copies of the items and profile on screen which have changed.
+type updatedItemsMsg struct {
+	items []client.Item
+	user  *client.User
+}
+
+// scheduleUpdates waits until it's time to check for updates again.
+func scheduleUpdates() tea.Cmd {
+	return tea.Tick(updatesInterval, func(time.Time) tea.Msg {
+		return pollUpdatesMsg{}
+	})
+}
+
+// checkUpdates fetches fresh copies of whichever of the watched items and user have changed. The check isn't part of
+// a load, since it's not tied to any one screen.
+func checkUpdates(c *client.Client, watchedItemIds []int, watchedUserId string) tea.Msg {
+	ctx := context.Background()
+	updates, err := c.GetUpdates(ctx)
+	if err != nil {
+		log.Logger.Printf("Error checking for updates: %v", err)
+		return nil
+	}
+
+	var msg updatedItemsMsg
+	var changedIds []int
+	for _, itemId := range watchedItemIds {
+		if slices.Contains(updates.Items, itemId) {
+			changedIds = append(changedIds, itemId)
+		}
+	}
+	if len(changedIds) > 0 {
+		items, err := c.GetItems(ctx, changedIds)
+		if err != nil {
+			log.Logger.Printf("Error refreshing updated items: %v", err)
+		}
+		for _, item := range items {
+			if !item.Unavailable {
+				msg.items = append(msg.items, item)
+			}
+		}
+	}
+	if watchedUserId != "" && slices.Contains(updates.Profiles, watchedUserId) {
+		if user, err := c.GetUser(ctx, watchedUserId); err == nil {
+			msg.user = &user
+		}
+	}
+	return msg
+}
+
+// PollUpdates checks whether any of the items or the profile on screen have changed.
+func (m model) PollUpdates() tea.Cmd {
+	itemIds := m.watchedItemIds()
+	userId := ""
+	if m.profile != nil {
+		userId = m.profile.user.Id
+	}
+	return func() tea.Msg {
+		return checkUpdates(m.client, itemIds, userId)
+	}
+}
+
+// watchedItemIds returns the IDs of the items on screen, which we keep up to date as they change.
+func (m model) watchedItemIds() []int {
+	var itemIds []int
+	if topic := m.getCurrentTopic(); topic != nil {
+		var walk func(item client.Item)
+		walk = func(item client.Item) {
+			itemIds = append(itemIds, item.Id)
+			for _, comment := range item.Comments {
+				walk(comment)
+			}
+		}
+		walk(*topic)
+		return itemIds
+	}
+	items := m.listedItems()
+	start := min((m.currentPage-1)*m.pageSize, len(items))
+	end := min(start+m.pageSize, len(items))
+	for _, item := range items[start:end] {
+		if item.Type != "" {
+			itemIds = append(itemIds, item.Id)
+		}
+	}
+	return itemIds
+}
+
+// applyItemUpdate copies an updated item over the one we have, keeping the replies and poll options we've already
+// loaded. It reports whether the item has new replies.
+func applyItemUpdate(item *client.Item, updated client.Item) bool {
+	newReplies := len(updated.Kids) > len(item.Kids)
+	comments, options := item.Comments, item.Options
+	*item = updated
+	item.Comments, item.Options = comments, options
+	return newReplies
+}
+
+// applyUpdates updates the items we have with fresh copies, wherever they are, and marks those with new replies.
+// The cursor is left where it is.
+func (m model) applyUpdates(msg updatedItemsMsg) model {
+	updated := map[int]client.Item{}
+	for _, item := range msg.items {
+		updated[item.Id] = item
+	}
+
+	apply := func(item *client.Item) {
+		if fresh, ok := updated[item.Id]; ok && applyItemUpdate(item, fresh) {
+			m.newReplies[item.Id] = true
+		}
+	}
+	for i := range m.topMenuResponse.Items {
+		apply(&m.topMenuResponse.Items[i])
+	}
+	var walk func(item *client.Item)
+	walk = func(item *client.Item) {
+		apply(item)
+		for i := range item.Comments {
+			walk(&item.Comments[i])
+		}
+	}
+	for i := range m.topicHistoryStack {
+		walk(&m.topicHistoryStack[i])
+	}
+
+	if msg.user != nil && m.profile != nil && m.profile.user.Id == msg.user.Id {
+		m.profile.user = *msg.user
+	}
+
+	if m.getCurrentTopic() != nil {
+		return m.setTopicChoices()
+	}
+	m.choices = getTopMenuCurrentPageChoices(m)
+	return m
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dominickp/hn/client"
+)
+
+func Test_model_applyUpdates(t *testing.T) {
+	topic := client.Item{Id: 1, Score: 10, Kids: []int{2}, Comments: []client.Item{
+		{Id: 2, Text: "old", Kids: []int{3}, Comments: []client.Item{{Id: 3, Text: "reply"}}},
+	}}
+	m := model{
+		topicHistoryStack: []client.Item{topic},
+		folded:            map[int]bool{},
+		newReplies:        map[int]bool{},
+		cursor:            1,
+	}
+	m = m.setTopicChoices()
+
+	m = m.applyUpdates(updatedItemsMsg{items: []client.Item{
+		{Id: 1, Score: 20, Kids: []int{2}},
+		{Id: 2, Text: "edited", Kids: []int{3, 4}},
+	}})
+
+	topicNow := m.getCurrentTopic()
+	if topicNow.Score != 20 || topicNow.Comments[0].Text != "edited" {
+		t.Errorf("applyUpdates() = %+v, want the topic's score and comment updated", topicNow)
+	}
+	if len(topicNow.Comments[0].Comments) != 1 {
+		t.Errorf("applyUpdates() should keep the replies we've already loaded")
+	}
+	if want := map[int]bool{2: true}; !reflect.DeepEqual(m.newReplies, want) {
+		t.Errorf("applyUpdates() newReplies = %v, want %v", m.newReplies, want)
+	}
+	if m.cursor != 1 || len(m.comments) != 2 || !m.comments[0].newReplies {
+		t.Errorf("applyUpdates() should redraw the comments without moving the cursor")
+	}
+}
+
+func Test_model_watchedItemIds(t *testing.T) {
+	m := model{
+		currentPage: 2,
+		pageSize:    2,
+		topMenuResponse: client.TopMenuResponse{Items: []client.Item{
+			{Id: 1, Type: "story"}, {Id: 2, Type: "story"}, {Id: 3, Type: "story"}, {Id: 4}, {Id: 5, Type: "story"},
+		}},
+	}
+	// Only the stories we've loaded on the current page are watched
+	if got, want := m.watchedItemIds(), []int{3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("watchedItemIds() = %v, want %v", got, want)
+	}
+
+	m.topicHistoryStack = []client.Item{{Id: 1, Comments: []client.Item{{Id: 6, Comments: []client.Item{{Id: 7}}}}}}
+	if got, want := m.watchedItemIds(), []int{1, 6, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("watchedItemIds() in a topic = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Highlight controls whether code blocks are syntax highlighted. Turn it off for monochrome terminals.
+var Highlight = true
+
+// language describes enough of a programming language's syntax to highlight it, and to guess that a snippet of code
+// is written in it.
+type language struct {
+	name         string
+	keywords     map[string]bool
+	ignoreCase   bool      // Whether keywords can be written in any case
+	lineComments []string  // What starts a comment running to the end of the line
+	blockComment [2]string // What starts and ends a comment which can span lines, if the language has them
+	quotes       string    // The characters strings can be quoted with
+	hints        []*regexp.Regexp
+}
+
+// languages are the languages we can recognise, most likely first, as ties are won by the language listed first.
+var languages = []*language{
+	{
+		name: "go",
+		keywords: keywords("break case chan const continue default defer else fallthrough for func go goto if import " +
+			"interface map package range return select struct switch type var nil true false"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		hints:        hints(`^\s*package \w+`, `^\s*func\b`, `:=`, `\bfmt\.`, `\berr != nil\b`, `^\s*import \(`),
+	},
+	{
+		name: "python",
+		keywords: keywords("and as assert async await break class continue def del elif else except finally for from " +
+			"global if import in is lambda nonlocal not or pass raise return try while with yield None True False self"),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		hints: hints(`^\s*def \w+\(.*\):`, `^\s*(from \S+ )?import \w+`, `\bself\b`, `\bNone\b`, `\bprint\(`,
+			`^\s*(if|elif|else|for|while|class|try|except|with)\b.*:\s*$`),
+	},
+	{
+		name: "javascript",
+		keywords: keywords("async await break case catch class const continue default delete do else export extends " +
+			"finally for from function if import in instanceof let new of return switch this throw try typeof var " +
+			"void while yield null undefined true false interface type"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		hints: hints(`\b(const|let|var) \w+ =`, `=>`, `\bfunction\b`, `\bconsole\.`, `\brequire\(`,
+			`^\s*import .* from `, `===`),
+	},
+	{
+		name: "rust",
+		keywords: keywords("as async await break const continue crate dyn else enum extern false fn for if impl in let " +
+			"loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+		hints:        hints(`\bfn \w+`, `\blet mut\b`, `\bimpl\b`, `\w::\w`, `\w+!\(`, `^\s*use \w+::`),
+	},
+	{
+		name: "c",
+		keywords: keywords("auto break case char const continue default do double else enum extern float for goto if " +
+			"int long register return short signed sizeof static struct switch typedef union unsigned void volatile " +
+			"while class namespace public private protected template typename new delete bool true false nullptr NULL"),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		hints:        hints(`^\s*#include\b`, `^\s*#define\b`, `\b(int|void|char|size_t) \*?\w+\(`, `\bprintf\(`, `\bstd::`),
+	},
+	{
+		name: "shell",
+		keywords: keywords("if then else elif fi for while until do done case esac function in return export local " +
+			"sudo cd echo"),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		hints: hints(`^\s*\$ `, `^\s*#!/`, `\|\s*(grep|sed|awk|xargs|sort|head|tail|jq)\b`,
+			`^\s*(sudo|apt(-get)?|brew|curl|wget|echo|export|cd|git|npm|pip|docker|make|go (run|build|install))\b`),
+	},
+	{
+		name: "sql",
+		keywords: keywords("select from where and or not insert into values update set delete create table index " +
+			"drop alter join left right inner outer on group by order having limit as distinct union with null is in " +
+			"like between case when then else end primary key"),
+		ignoreCase:   true,
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "'\"",
+		hints: hints(`(?i)^\s*(select|insert|update|delete|create|alter|with)\b`,
+			`(?i)\b(from|where|join|group by|order by)\b`),
+	},
+}
+
+// keywords returns a set of the space separated words in s.
+func keywords(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.Fields(s) {
+		set[word] = true
+	}
+	return set
+}
+
+// hints compiles the patterns which lines written in a language tend to match.
+func hints(patterns ...string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		compiled = append(compiled, regexp.MustCompile(pattern))
+	}
+	return compiled
+}
+
+// detectLanguage guesses the language code is written in by the number of lines matching each language's hints, or
+// returns nil if nothing about it stands out.
+func detectLanguage(code string) *language {
+	var best *language
+	bestScore := 0
+	for _, lang := range languages {
+		score := 0
+		for _, line := range strings.Split(code, "\n") {
+			for _, hint := range lang.hints {
+				if hint.MatchString(line) {
+					score++
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore = lang, score
+		}
+	}
+	return best
+}
+
+// highlight styles the keywords, strings, numbers and comments in lines of code.
+func (l *language) highlight(lines []string) []string {
+	highlighted := make([]string, len(lines))
+	inComment := false // Whether we're in a block comment carried over from a previous line
+	for i, line := range lines {
+		var b strings.Builder
+		plain := 0 // Where the text we haven't styled yet starts
+		flush := func(end int) {
+			b.WriteString(line[plain:end])
+		}
+		for j := 0; j < len(line); {
+			rest := line[j:]
+			end := j
+			style := CodeCommentStyle
+			switch {
+			case inComment || l.blockComment[0] != "" && strings.HasPrefix(rest, l.blockComment[0]):
+				start := 0
+				if !inComment {
+					start = len(l.blockComment[0])
+				}
+				if k := strings.Index(rest[start:], l.blockComment[1]); k >= 0 {
+					end, inComment = j+start+k+len(l.blockComment[1]), false
+				} else {
+					end, inComment = len(line), true
+				}
+			case l.isLineComment(line, j):
+				end = len(line)
+			case strings.ContainsRune(l.quotes, rune(line[j])):
+				end, style = closingQuote(line, j)+1, CodeStringStyle
+			case isDigit(line[j]) && (j == 0 || !isWordByte(line[j-1])):
+				end, style = wordEnd(line, j), CodeNumberStyle
+			case isWordByte(line[j]) && (j == 0 || !isWordByte(line[j-1])):
+				end = wordEnd(line, j)
+				word := line[j:end]
+				if l.ignoreCase {
+					word = strings.ToLower(word)
+				}
+				if !l.keywords[word] {
+					j = end
+					continue
+				}
+				style = CodeKeywordStyle
+			default:
+				j++
+				continue
+			}
+			flush(j)
+			b.WriteString(style.Render(line[j:end]))
+			j, plain = end, end
+		}
+		flush(len(line))
+		highlighted[i] = b.String()
+	}
+	return highlighted
+}
+
+// isLineComment reports whether a comment running to the end of the line starts at line[i]. Comments have to start
+// the line or follow a space, so the likes of URLs and ${#array} aren't taken for them.
+func (l *language) isLineComment(line string, i int) bool {
+	if i > 0 && line[i-1] != ' ' && line[i-1] != '\t' {
+		return false
+	}
+	for _, prefix := range l.lineComments {
+		if strings.HasPrefix(line[i:], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// closingQuote returns the index of the quote closing the string opened at line[start], skipping escaped quotes, or
+// the last index of the line if the string isn't closed.
+func closingQuote(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case line[start]:
+			return i
+		}
+	}
+	return len(line) - 1
+}
+
+// wordEnd returns the index just past the word starting at line[start].
+func wordEnd(line string, start int) int {
+	end := start
+	for end < len(line) && isWordByte(line[end]) {
+		end++
+	}
+	return end
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,129 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func Test_detectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{
+			name: "TestGo",
+			code: "  func main() {\n      if err := run(); err != nil {\n          log.Fatal(err)\n      }\n  }",
+			want: "go",
+		},
+		{
+			name: "TestPython",
+			code: "  def fib(n):\n      if n < 2:\n          return n\n      return fib(n - 1) + fib(n - 2)",
+			want: "python",
+		},
+		{
+			name: "TestJavaScript",
+			code: "  const total = items.reduce((sum, item) => sum + item.price, 0);\n  console.log(total);",
+			want: "javascript",
+		},
+		{
+			name: "TestRust",
+			code: "  fn main() {\n      let mut v = Vec::new();\n      v.push(1);\n      println!(\"{:?}\", v);\n  }",
+			want: "rust",
+		},
+		{
+			name: "TestC",
+			code: "  #include <stdio.h>\n  int main(void) {\n      printf(\"hello\\n\");\n  }",
+			want: "c",
+		},
+		{
+			name: "TestShell",
+			code: "  $ curl -s https://example.com/api | jq .items\n  $ sudo make install",
+			want: "shell",
+		},
+		{
+			name: "TestSQL",
+			code: "  SELECT id, title\n  FROM stories\n  WHERE score > 100\n  ORDER BY time DESC;",
+			want: "sql",
+		},
+		{
+			name: "TestNotCode",
+			code: "  Roses are red\n  Violets are blue",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if lang := detectLanguage(tt.code); lang != nil {
+				got = lang.name
+			}
+			if got != tt.want {
+				t.Errorf("detectLanguage() = '%v', want '%v'", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_language_highlight(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(termenv.Ascii)
+
+	keyword := CodeKeywordStyle.Render
+	str := CodeStringStyle.Render
+	number := CodeNumberStyle.Render
+	comment := CodeCommentStyle.Render
+	tests := []struct {
+		name  string
+		lang  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "TestGo",
+			lang:  "go",
+			lines: []string{`for i := range 10 { // count`, `    fmt.Println("i is", i)`},
+			want:  []string{keyword("for") + " i := " + keyword("range") + " " + number("10") + " { " + comment("// count"), `    fmt.Println(` + str(`"i is"`) + `, i)`},
+		},
+		{
+			name:  "TestBlockComment",
+			lang:  "c",
+			lines: []string{"int x; /* spans", "lines */ return x;"},
+			want:  []string{keyword("int") + " x; " + comment("/* spans"), comment("lines */") + " " + keyword("return") + " x;"},
+		},
+		{
+			name:  "TestEscapedQuote",
+			lang:  "python",
+			lines: []string{`print("say \"hi\"")`},
+			want:  []string{`print(` + str(`"say \"hi\""`) + `)`},
+		},
+		{
+			name:  "TestNotAComment",
+			lang:  "shell",
+			lines: []string{`echo ${#items} http://example.com/#top`},
+			want:  []string{keyword("echo") + ` ${#items} http://example.com/#top`},
+		},
+		{
+			name:  "TestIgnoreCase",
+			lang:  "sql",
+			lines: []string{`select * FROM t -- all`},
+			want:  []string{keyword("select") + " * " + keyword("FROM") + " t " + comment("-- all")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lang *language
+			for _, l := range languages {
+				if l.name == tt.lang {
+					lang = l
+				}
+			}
+			if got := lang.highlight(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("highlight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
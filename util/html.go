@@ -0,0 +1,225 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	h "golang.org/x/net/html"
+)
+
+// span is a run of text within a paragraph, along with its style.
+type span struct {
+	text   string
+	style  lipgloss.Style
+	styled bool // Whether text has been styled already, as links are
+}
+
+// renderer lays out the HTML of a hackernews text message as a series of blocks: paragraphs, which are word wrapped,
+// and code blocks, which are kept as they are.
+type renderer struct {
+	width  int                            // The width to wrap to, or 0 not to wrap at all
+	plain  bool                           // Whether to leave the text unstyled
+	link   func(href, text string) string // Renders a link
+	blocks []string
+	spans  []span // The paragraph being built
+}
+
+// PlainText converts a hackernews text message to plain text without any styling, so it can be searched and cut
+// short safely. Links are shown as their URLs, quotes keep their ">" and nothing is wrapped.
+func PlainText(s string) string {
+	doc, err := h.Parse(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+	r := renderer{plain: true, link: func(href, text string) string { return href }}
+	return r.render(doc)
+}
+
+// renderHtml converts a hackernews text message to text wrapped to width, replacing each link with what link
+// returns for it. Paragraphs are separated by blank lines.
+func renderHtml(s string, width int, link func(href, text string) string) string {
+	doc, err := h.Parse(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+	r := renderer{width: width, link: link}
+	return r.render(doc)
+}
+
+// render renders a parsed message, with its paragraphs separated by blank lines.
+func (r *renderer) render(doc *h.Node) string {
+	r.walk(doc, lipgloss.NewStyle())
+	r.endParagraph()
+	return strings.Join(r.blocks, "\n\n")
+}
+
+// walk renders a node and everything beneath it, in the style of the elements it's nested in.
+func (r *renderer) walk(n *h.Node, style lipgloss.Style) {
+	switch {
+	case n.Type == h.TextNode:
+		r.spans = append(r.spans, span{text: collapseSpace(n.Data), style: style})
+		return
+	case n.Type != h.ElementNode:
+	case n.Data == "p":
+		r.endParagraph()
+		defer r.endParagraph()
+	case n.Data == "pre":
+		r.endParagraph()
+		r.blocks = append(r.blocks, r.renderCode(textContent(n)))
+		return
+	case n.Data == "i" || n.Data == "em":
+		style = style.Copy().Italic(true)
+	case n.Data == "a":
+		if href := attr(n, "href"); href != "" {
+			r.spans = append(r.spans, span{text: r.link(href, textContent(n)), styled: true})
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c, style)
+	}
+}
+
+// endParagraph adds the paragraph being built to the blocks. Paragraphs starting with ">" are quotes, shown with a
+// marker for each level of quoting.
+func (r *renderer) endParagraph() {
+	spans := r.spans
+	r.spans = nil
+	depth, spans := quoteDepth(spans)
+
+	var text string
+	spaced := true // Whether the text so far ends in a space, or there isn't any
+	for i, span := range spans {
+		if span.styled {
+			text += span.text
+			spaced = false
+			continue
+		}
+		if spaced {
+			span.text = strings.TrimLeft(span.text, " ")
+		}
+		if i == len(spans)-1 {
+			span.text = strings.TrimRight(span.text, " ")
+		}
+		if span.text == "" {
+			continue
+		}
+		spaced = strings.HasSuffix(span.text, " ")
+		if r.plain {
+			text += span.text
+			continue
+		}
+		if depth > 0 {
+			span.style = span.style.Copy().Inherit(QuoteStyle)
+		}
+		text += span.style.Render(span.text)
+	}
+	if text == "" {
+		return
+	}
+
+	if depth == 0 {
+		r.blocks = append(r.blocks, wrapText(text, r.width))
+		return
+	}
+	marker := QuoteStyle.Render(strings.Repeat("┃ ", depth))
+	if r.plain {
+		marker = strings.Repeat("> ", depth)
+	}
+	width := r.width
+	if width > 0 {
+		width = max(10, width-2*depth)
+	}
+	lines := strings.Split(wrapText(text, width), "\n")
+	for i := range lines {
+		lines[i] = marker + lines[i]
+	}
+	r.blocks = append(r.blocks, strings.Join(lines, "\n"))
+}
+
+// quoteDepth counts the ">" at the start of a paragraph, returning how deeply it's quoted and its spans without them.
+func quoteDepth(spans []span) (int, []span) {
+	depth := 0
+	for len(spans) > 0 && !spans[0].styled {
+		text := strings.TrimLeft(spans[0].text, " ")
+		for strings.HasPrefix(text, ">") {
+			depth++
+			text = strings.TrimLeft(text[1:], " ")
+		}
+		if text != "" {
+			spans[0].text = text
+			break
+		}
+		spans = spans[1:]
+	}
+	return depth, spans
+}
+
+// renderCode renders a code block line for line, cutting short any lines too wide to fit rather than wrapping them.
+// When we're wrapping, the block is drawn in a box and highlighted in the language it looks to be written in.
+func (r *renderer) renderCode(code string) string {
+	width := r.width
+	if width > 0 {
+		width = max(10, width-CodeBoxStyle.GetHorizontalFrameSize())
+	}
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+	for i, line := range lines {
+		line = strings.ReplaceAll(strings.TrimRight(line, " "), "\t", "    ")
+		if width > 0 {
+			line = Truncate(line, width)
+		}
+		lines[i] = line
+	}
+	if r.width == 0 {
+		return strings.Join(lines, "\n")
+	}
+
+	if lang := detectLanguage(code); Highlight && lang != nil {
+		lines = lang.highlight(lines)
+	} else if Highlight {
+		for i, line := range lines {
+			lines[i] = CodeStyle.Render(line)
+		}
+	}
+	return CodeBoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// collapseSpace collapses each run of whitespace in s to a single space, as browsers do.
+func collapseSpace(s string) string {
+	text := strings.Join(strings.Fields(s), " ")
+	if text == "" {
+		if s == "" {
+			return ""
+		}
+		return " "
+	}
+	if strings.TrimLeft(s, " \t\n") != s {
+		text = " " + text
+	}
+	if strings.TrimRight(s, " \t\n") != s {
+		text += " "
+	}
+	return text
+}
+
+// textContent returns all the text beneath a node, as it is in the HTML.
+func textContent(n *h.Node) string {
+	if n.Type == h.TextNode {
+		return n.Data
+	}
+	var text string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		text += textContent(c)
+	}
+	return text
+}
+
+// attr returns the value of a node's attribute, or "" if it doesn't have it.
+func attr(n *h.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestPlainText(t *testing.T) {
+	// Styles would show up here if there were any
+	lipgloss.SetColorProfile(termenv.ANSI)
+	defer lipgloss.SetColorProfile(termenv.Ascii)
+
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "TestReplaceP",
+			args: args{s: "Hello<p>world</p>"},
+			want: "Hello\n\nworld",
+		},
+		{
+			name: "TestReplaceMultipleP",
+			args: args{s: "Hello<p>world.</p> Say hello to my <p>little friend!</p>"},
+			want: "Hello\n\nworld.\n\nSay hello to my\n\nlittle friend!",
+		},
+		{
+			name: "TestItalicizeI",
+			args: args{s: "Hello <i>world</i>"},
+			want: "Hello world",
+		},
+		{
+			name: "TestNestedInItalics",
+			args: args{s: "Hello <i>big <a href=\"https://example.com\">world</a></i>!"},
+			want: "Hello big https://example.com!",
+		},
+		{
+			name: "TestLinkFormatting",
+			args: args{s: "This is a link: <a href=\"https://example.com\">example</a>"},
+			want: "This is a link: https://example.com",
+		},
+		{
+			name: "TestLinkFormattingWRel",
+			args: args{s: "This is a link: <a href=\"https://example.com\" rel=\"foo\">example</a>"},
+			want: "This is a link: https://example.com",
+		},
+		{
+			name: "TestLinkAttributesReordered",
+			args: args{s: "This is a link: <a rel=\"nofollow\" href=\"https://example.com\">example</a>"},
+			want: "This is a link: https://example.com",
+		},
+		{
+			name: "TestEscapedOnce",
+			args: args{s: "Use &amp;lt;p&amp;gt; for paragraphs"},
+			want: "Use &lt;p&gt; for paragraphs",
+		},
+		{
+			name: "TestQuotes",
+			args: args{s: "&gt; &gt; It&#x27;s just a wrapper<p><i>&gt; Not really</i><p>Fair."},
+			want: "> > It's just a wrapper\n\n> Not really\n\nFair.",
+		},
+		{
+			name: "TestCode",
+			args: args{s: "Try:<p><pre><code>  x := 1\n</code></pre>"},
+			want: "Try:\n\n  x := 1",
+		},
+		{
+			name: "TestEmpty",
+			args: args{s: ""},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PlainText(tt.args.s)
+			if got != tt.want {
+				t.Errorf("PlainText() = '%v', want '%v'", got, tt.want)
+			}
+		})
+	}
+}
+
+// The messages below are as the API returns them for real HN comments.
+func TestRenderHtml_comments(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{
+			name:  "TestParagraphs",
+			s:     "I&#x27;ve been running this in production for two years.<p>The trick is to <i>not</i> use the default config, it&#x27;s tuned for laptops.",
+			width: 40,
+			want: "I've been running this in production for\ntwo years.\n\n" +
+				"The trick is to not use the default\nconfig, it's tuned for laptops.",
+		},
+		{
+			name:  "TestQuote",
+			s:     "&gt; Why would anyone write a database in Go?<p>Because the GC pauses are shorter than you&#x27;d think.",
+			width: 40,
+			want: "┃ Why would anyone write a database in\n┃ Go?\n\n" +
+				"Because the GC pauses are shorter than\nyou'd think.",
+		},
+		{
+			name:  "TestNestedQuotes",
+			s:     "&gt; &gt; It&#x27;s just a wrapper around SQLite.<p>&gt;&gt; Not really, it has its own storage engine.<p>&gt; Which is a wrapper around SQLite.<p>Fair.",
+			width: 40,
+			want: "┃ ┃ It's just a wrapper around SQLite.\n\n" +
+				"┃ ┃ Not really, it has its own storage\n┃ ┃ engine.\n\n" +
+				"┃ Which is a wrapper around SQLite.\n\n" +
+				"Fair.",
+		},
+		{
+			name:  "TestItalicQuote",
+			s:     "<i>&gt; The default is 30 seconds</i><p>Only since 2.0.",
+			width: 40,
+			want:  "┃ The default is 30 seconds\n\nOnly since 2.0.",
+		},
+		{
+			name:  "TestCode",
+			s:     "Try this:<p><pre><code>  for i := range items {\n      fmt.Println(items[i].Name)\n  }\n</code></pre>\nWorks for me.",
+			width: 40,
+			want: "Try this:\n\n" +
+				"╭──────────────────────────────────╮\n" +
+				"│   for i := range items {         │\n" +
+				"│       fmt.Println(items[i].Name) │\n" +
+				"│   }                              │\n" +
+				"╰──────────────────────────────────╯\n\n" +
+				"Works for me.",
+		},
+		{
+			name:  "TestCodeNotWrapped",
+			s:     "<pre><code>  SELECT id, title FROM stories WHERE score &gt; 100 ORDER BY time DESC;\n</code></pre>",
+			width: 40,
+			want: "╭──────────────────────────────────────╮\n" +
+				"│   SELECT id, title FROM stories WHE… │\n" +
+				"╰──────────────────────────────────────╯",
+		},
+		{
+			name:  "TestCodeInPlainText",
+			s:     "<pre><code>  $ go install github.com&#x2F;dominickp&#x2F;hn@latest\n</code></pre>",
+			width: 0,
+			want:  "  $ go install github.com/dominickp/hn@latest",
+		},
+		{
+			name:  "TestLink",
+			s:     "The docs are here: <a href=\"https:&#x2F;&#x2F;go.dev&#x2F;doc&#x2F;effective_go\" rel=\"nofollow\">https:&#x2F;&#x2F;go.dev&#x2F;doc&#x2F;effective_go</a>",
+			width: 80,
+			want:  "The docs are here: go.dev/doc/effective_go[1]\n\n[1] https://go.dev/doc/effective_go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderHtml(tt.s, tt.width); got != tt.want {
+				t.Errorf("RenderHtml() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+// Hyperlinks controls whether RenderHtml shows links as clickable OSC 8 hyperlinks, rather than as footnotes.
+var Hyperlinks = false
+
+// maxLinkLabel is how long a link labelled with its own URL can be once shortened.
+const maxLinkLabel = 40
+
+// resetSGR turns off text styling. Lipgloss and Bubble Tea think an escape sequence runs until the first letter, so
+// they count most of a hyperlink's URL as printable. Following each OSC 8 sequence with a reset stops them counting,
+// or swallowing, what comes after it as well.
+const resetSGR = "\x1b[0m"
+
+// SupportsHyperlinks reports whether the terminal we're running in shows OSC 8 hyperlinks, going by the environment
+// variables terminals set. HN_HYPERLINKS=1 or HN_HYPERLINKS=0 overrides the guess.
+func SupportsHyperlinks(getenv func(string) string) bool {
+	if on, err := strconv.ParseBool(getenv("HN_HYPERLINKS")); err == nil {
+		return on
+	}
+	if getenv("TERM") == "dumb" {
+		return false
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	for _, name := range []string{"KITTY_WINDOW_ID", "WT_SESSION", "ALACRITTY_WINDOW_ID", "KONSOLE_VERSION", "DOMTERM"} {
+		if getenv(name) != "" {
+			return true
+		}
+	}
+	// GNOME Terminal and the other VTE based terminals have supported them since 0.50
+	version, err := strconv.Atoi(getenv("VTE_VERSION"))
+	return err == nil && version >= 5000
+}
+
+// RenderHtml converts a hackernews text message to text word wrapped to width. Links are OSC 8 hyperlinks if
+// Hyperlinks is set and they fit on a line, otherwise they're numbered and their URLs listed as footnotes at the end.
+func RenderHtml(s string, width int) string {
+	var footnotes []string
+	text := renderHtml(s, width, func(href, text string) string {
+		label := linkLabel(href, text)
+		if Hyperlinks {
+			if link := hyperlink(href, label); ansi.PrintableRuneWidth(link) <= width {
+				return link
+			}
+		}
+		n := len(footnotes) + 1
+		for i, footnote := range footnotes {
+			if footnote == href {
+				n = i + 1
+			}
+		}
+		if n > len(footnotes) {
+			footnotes = append(footnotes, href)
+		}
+		return LinkStyle.Render(label) + FootnoteStyle.Render(fmt.Sprintf("[%d]", n))
+	})
+
+	if len(footnotes) > 0 {
+		text += "\n"
+		for i, href := range footnotes {
+			text += "\n" + FootnoteStyle.Render(fmt.Sprintf("[%d] %s", i+1, href))
+		}
+	}
+	return text
+}
+
+// hyperlink returns label as an OSC 8 hyperlink to url.
+func hyperlink(url, label string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + resetSGR + LinkStyle.Render(label) + "\x1b]8;;\x1b\\" + resetSGR
+}
+
+// linkLabel returns the text to show for a link: its anchor text, or its URL shortened to the domain and path if
+// the anchor is just the URL. HN makes links of URLs pasted into comments, cutting the anchor short with "..." if
+// they're long.
+func linkLabel(href, text string) string {
+	prefix, cut := strings.CutSuffix(text, "...")
+	if text != href && !(cut && strings.HasPrefix(href, prefix)) {
+		return text
+	}
+	u, err := url.Parse(href)
+	if err != nil || u.Host == "" {
+		return Truncate(href, maxLinkLabel)
+	}
+	return Truncate(strings.TrimPrefix(u.Host, "www.")+strings.TrimSuffix(u.Path, "/"), maxLinkLabel)
+}
+
+// wrapText word wraps s to width as lipgloss would, except that it never breaks a line inside a hyperlink. Widths are
+// measured the way lipgloss and Bubble Tea measure them, so they don't wrap or truncate the lines any further. A width
+// of 0 leaves s as it is.
+func wrapText(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		current, currentWidth := "", 0
+		for i, word := range splitWords(line) {
+			wordWidth := ansi.PrintableRuneWidth(word)
+			if i > 0 && currentWidth+1+wordWidth > width {
+				lines = append(lines, current)
+				current, currentWidth = word, wordWidth
+				continue
+			}
+			if i > 0 {
+				current += " "
+				currentWidth++
+			}
+			current += word
+			currentWidth += wordWidth
+		}
+		lines = append(lines, current)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitWords splits a line into words at its spaces, leaving the spaces within hyperlinks alone.
+func splitWords(line string) []string {
+	var words []string
+	inLink := false
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch {
+		case strings.HasPrefix(line[i:], "\x1b]8;;\x1b\\"):
+			inLink = false
+		case strings.HasPrefix(line[i:], "\x1b]8;;"):
+			inLink = true
+		case line[i] == ' ' && !inLink:
+			words = append(words, line[start:i])
+			start = i + 1
+		}
+	}
+	return append(words, line[start:])
+}
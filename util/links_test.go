@@ -0,0 +1,131 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+func TestSupportsHyperlinks(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "TestITerm", env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: true},
+		{name: "TestKitty", env: map[string]string{"KITTY_WINDOW_ID": "1"}, want: true},
+		{name: "TestNewVte", env: map[string]string{"VTE_VERSION": "7600"}, want: true},
+		{name: "TestOldVte", env: map[string]string{"VTE_VERSION": "4802"}, want: false},
+		{name: "TestAppleTerminal", env: map[string]string{"TERM_PROGRAM": "Apple_Terminal"}, want: false},
+		{name: "TestDumb", env: map[string]string{"TERM": "dumb", "WT_SESSION": "1"}, want: false},
+		{name: "TestOverrideOn", env: map[string]string{"HN_HYPERLINKS": "1"}, want: true},
+		{name: "TestOverrideOff", env: map[string]string{"HN_HYPERLINKS": "0", "TERM_PROGRAM": "WezTerm"}, want: false},
+		{name: "TestEmpty", env: map[string]string{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			if got := SupportsHyperlinks(getenv); got != tt.want {
+				t.Errorf("SupportsHyperlinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_linkLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		href string
+		text string
+		want string
+	}{
+		{name: "TestAnchorText", href: "https://example.com/docs", text: "the docs", want: "the docs"},
+		{name: "TestBareUrl", href: "https://www.example.com/docs/", text: "https://www.example.com/docs/", want: "example.com/docs"},
+		{
+			name: "TestCutShortUrl",
+			href: "https://github.com/dominickp/hn/blob/main/util/util.go?plain=1",
+			text: "https://github.com/dominickp/hn/blob/main/util/...",
+			want: "github.com/dominickp/hn/blob/main/util/…",
+		},
+		{name: "TestNotAUrl", href: "item?id=1", text: "item?id=1", want: "item?id=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkLabel(tt.href, tt.text); got != tt.want {
+				t.Errorf("linkLabel() = '%v', want '%v'", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderHtml(t *testing.T) {
+	link := func(url, label string) string {
+		return "\x1b]8;;" + url + "\x1b\\\x1b[0m" + label + "\x1b]8;;\x1b\\\x1b[0m"
+	}
+	tests := []struct {
+		name       string
+		s          string
+		width      int
+		hyperlinks bool
+		want       string
+	}{
+		{
+			name:  "TestFootnotes",
+			s:     `See <a href="https:&#x2F;&#x2F;example.com&#x2F;a" rel="nofollow">the docs</a> and <a href="https:&#x2F;&#x2F;example.com&#x2F;b" rel="nofollow">https:&#x2F;&#x2F;example.com&#x2F;b</a>`,
+			width: 80,
+			want:  "See the docs[1] and example.com/b[2]\n\n[1] https://example.com/a\n[2] https://example.com/b",
+		},
+		{
+			name:  "TestRepeatedLink",
+			s:     `<a href="https://example.com">here</a> or <a href="https://example.com">here</a>`,
+			width: 80,
+			want:  "here[1] or here[1]\n\n[1] https://example.com",
+		},
+		{
+			name:       "TestHyperlinks",
+			s:          `See <a href="https://example.com/a">the docs</a>`,
+			width:      80,
+			hyperlinks: true,
+			want:       "See " + link("https://example.com/a", "the docs"),
+		},
+		{
+			name:       "TestHyperlinkTooWide",
+			s:          `See <a href="https://example.com/a">the docs</a>`,
+			width:      20,
+			hyperlinks: true,
+			want:       "See the docs[1]\n\n[1] https://example.com/a",
+		},
+		{
+			name:       "TestWrapsAroundHyperlinks",
+			s:          `Some words before <a href="https://example.com">a link</a> and after`,
+			width:      30,
+			hyperlinks: true,
+			want:       "Some words before\n" + link("https://example.com", "a link") + " and\nafter",
+		},
+		{
+			name:  "TestWraps",
+			s:     "The quick brown fox<p>jumps over the lazy dog",
+			width: 10,
+			want:  "The quick\nbrown fox\n\njumps over\nthe lazy\ndog",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Hyperlinks = tt.hyperlinks
+			defer func() { Hyperlinks = false }()
+
+			got := RenderHtml(tt.s, tt.width)
+			if got != tt.want {
+				t.Errorf("RenderHtml() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hyperlink(t *testing.T) {
+	// Lipgloss and Bubble Tea should count the URL, less its first letter, and the label, but nothing after the link
+	got := ansi.PrintableRuneWidth(hyperlink("https://example.com", "example") + ", then")
+	if want := len("ttps://example.com") + len("example, then"); got != want {
+		t.Errorf("hyperlink() is %d wide to lipgloss, want %d", got, want)
+	}
+}
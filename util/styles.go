@@ -25,6 +25,27 @@ var (
 	QuoteStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
 	TitleStyle       = lipgloss.NewStyle().Bold(true)
 
+	UnavailableStyle  = lipgloss.NewStyle().Italic(true).Foreground(lipgloss.Color("8"))
+	ErrorStyle        = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("1"))
+	OfflineBadgeStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("3")).Padding(0, 1)
+	WarningBadgeStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("1")).Padding(0, 1)
+
+	FeedTabStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	ActiveFeedTabStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+
 	CommentAuthorStyle = lipgloss.NewStyle().Bold(false).Foreground(lipgloss.Color("8"))
 	CommentTextStyle   = lipgloss.NewStyle().MarginLeft(4).PaddingBottom(1)
+	NewRepliesStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3"))
+	ThreadGuideStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	PollBarStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	SearchMatchStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3"))
+	ReadStyle          = lipgloss.NewStyle().Faint(true)
+	BookmarkStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))
+	FootnoteStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	CodeStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	CodeBoxStyle       = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")).Padding(0, 1)
+	CodeKeywordStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))
+	CodeStringStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	CodeNumberStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	CodeCommentStyle   = lipgloss.NewStyle().Italic(true).Foreground(lipgloss.Color("8"))
 )
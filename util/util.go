@@ -1,11 +1,8 @@
 package util
 
 import (
-	"bufio"
-	"html"
-	"strings"
-
-	h "golang.org/x/net/html"
+	"fmt"
+	"time"
 )
 
 func PadRight(str string, length int) string {
@@ -17,68 +14,39 @@ func PadRight(str string, length int) string {
 	}
 }
 
-// colorizeQuoteLines colorizes lines that start with ">" in a string.
-func colorizeQuoteLines(s string) string {
-	scanner := bufio.NewScanner(strings.NewReader(s))
-	var lines []string
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmedLine, ">") {
-			line = QuoteStyle.Render(line)
-		}
-		lines = append(lines, line)
+// Truncate shortens a string to at most width characters, ending it with an ellipsis if anything was cut off.
+func Truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
 	}
-	return strings.Join(lines, "\n")
+	return string(runes[:width-1]) + "…"
 }
 
-// htmlToText converts a hackernews text message which may contain HTML (like <p> tags) to plain text.
-func HtmlToText(s string) string {
-	s = html.UnescapeString(s)
-	// Replace <p> tags with newlines
-	s = strings.ReplaceAll(s, "<p>", "\n")
-	s = strings.ReplaceAll(s, "</p>", "\n")
-
-	doc, _ := h.Parse(strings.NewReader(s))
-	var f func(*h.Node)
-	f = func(n *h.Node) {
-		if n.Type == h.ElementNode && n.Data == "i" {
-			// Italicize text within <i> tags
-			if n.FirstChild != nil {
-				italicText := n.FirstChild.Data
-				styledText := ItalicStyle.Render(italicText)
-				s = strings.Replace(s, "<i>"+italicText+"</i>", styledText, -1)
-			}
-		} else if n.Data == "a" {
-			// Colorize and simplify links in <a> tags
-			var href string
-			var rel string
-
-			for _, a := range n.Attr {
-				if a.Key == "href" {
-					href = a.Val
-				}
-				if a.Key == "rel" {
-					rel = a.Val
-				}
-			}
-			if n.FirstChild != nil {
-				linkText := n.FirstChild.Data
-				styledText := LinkStyle.Render(href)
-				if rel != "" {
-					s = strings.Replace(s, `<a href="`+href+`" rel="`+rel+`">`+linkText+`</a>`, styledText, -1)
-				} else {
-					s = strings.Replace(s, `<a href="`+href+`">`+linkText+`</a>`, styledText, -1)
-				}
+// HumanizeDuration returns a rough, human friendly description of a duration, e.g. "3 years" or "1 hour".
+func HumanizeDuration(d time.Duration) string {
+	units := []struct {
+		name   string
+		length time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if n := int(d / unit.length); n > 0 {
+			if n == 1 {
+				return fmt.Sprintf("1 %s", unit.name)
 			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
+			return fmt.Sprintf("%d %ss", n, unit.name)
 		}
 	}
-	f(doc)
-	s = colorizeQuoteLines(s)
-	return s
+	return "less than a minute"
 }
 
 func Max(a, b int) int {
@@ -2,6 +2,7 @@ package util
 
 import (
 	"testing"
+	"time"
 )
 
 func TestPadRight(t *testing.T) {
@@ -39,80 +40,42 @@ func TestPadRight(t *testing.T) {
 	}
 }
 
-func TestHtmlToText(t *testing.T) {
-	type args struct {
-		s string
-	}
+func TestHumanizeDuration(t *testing.T) {
 	tests := []struct {
 		name string
-		args args
+		d    time.Duration
 		want string
 	}{
-		{
-			name: "TestReplaceP",
-			args: args{s: "Hello<p>world</p>"},
-			want: "Hello\nworld",
-		},
-		{
-			name: "TestReplaceMultipleP",
-			args: args{s: "Hello<p>world.</p> Say hello to my <p>little friend!</p>"},
-			want: "Hello\nworld.\n Say hello to my \nlittle friend!",
-		},
-		{
-			name: "TestItalicizeI",
-			args: args{s: "Hello <i>world</i>"},
-			want: "Hello world", // I'm not sure why ansi escape codes are not being rendered here
-		},
-		{
-			name: "TestLinkFormatting",
-			args: args{s: "This is a link: <a href=\"https://example.com\">example</a>"},
-			want: "This is a link: https://example.com",
-		},
-		{
-			name: "TestLinkFormattingWRel",
-			args: args{s: "This is a link: <a href=\"https://example.com\" rel=\"foo\">example</a>"},
-			want: "This is a link: https://example.com",
-		},
-		{
-			name: "TestEmpty",
-			args: args{s: ""},
-			want: "",
-		},
+		{name: "TestYears", d: 3*365*24*time.Hour + time.Hour, want: "3 years"},
+		{name: "TestOneMonth", d: 45 * 24 * time.Hour, want: "1 month"},
+		{name: "TestHours", d: 5*time.Hour + 59*time.Minute, want: "5 hours"},
+		{name: "TestSeconds", d: 30 * time.Second, want: "less than a minute"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := HtmlToText(tt.args.s)
-			if got != tt.want {
-				t.Errorf("HtmlToText() = '%v', want '%v'", got, tt.want)
+			if got := HumanizeDuration(tt.d); got != tt.want {
+				t.Errorf("HumanizeDuration() = '%v', want '%v'", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_colorizeQuoteLines(t *testing.T) {
-	type args struct {
-		s string
-	}
+func TestTruncate(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want string
+		name  string
+		s     string
+		width int
+		want  string
 	}{
-		{
-			name: "TestQuote",
-			args: args{s: "> foo\nbar"},
-			want: "> foo\nbar", // I'm not sure why ansi escape codes are not being rendered here
-		},
-		{
-			name: "TestEmpty",
-			args: args{s: ""},
-			want: "",
-		},
+		{name: "TestShortEnough", s: "hello", width: 5, want: "hello"},
+		{name: "TestTruncated", s: "hello world", width: 6, want: "hello…"},
+		{name: "TestMultibyte", s: "héllo wörld", width: 7, want: "héllo …"},
+		{name: "TestNoRoom", s: "hello", width: 0, want: ""},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := colorizeQuoteLines(tt.args.s); got != tt.want {
-				t.Errorf("colorizeQuoteLines() = '%v', want '%v'", got, tt.want)
+			if got := Truncate(tt.s, tt.width); got != tt.want {
+				t.Errorf("Truncate() = '%v', want '%v'", got, tt.want)
 			}
 		})
 	}